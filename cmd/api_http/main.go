@@ -5,9 +5,12 @@ import (
 	"github.com/glide-im/glideim/im/api"
 	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/dao"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/pkg/rpc"
+	"github.com/glide-im/glideim/service"
 	"github.com/glide-im/glideim/service/im_service"
+	"github.com/glide-im/glideim/service/messaging_service"
 )
 
 func main() {
@@ -19,11 +22,17 @@ func main() {
 
 	db.Init()
 	dao.Init()
+	err = webhook.Init()
+	if err != nil {
+		panic(err)
+	}
+	go webhook.Run()
 
 	// api 需要发送 gateway 和 group 消息
 	// 测试的时候 mock
 	//api.MockDep()
 	initIM()
+	initMessaging()
 
 	addr := config.ApiHttp.Addr
 	port := config.ApiHttp.Port
@@ -47,3 +56,16 @@ func initIM() {
 	}
 	client.SetInterfaceImpl(cli)
 }
+
+// initMessaging 服务端发送消息接口通过 rpc 交给消息服务处理
+func initMessaging() {
+
+	configs, err := service.GetConfig()
+	if err != nil {
+		panic(err)
+	}
+	err = messaging_service.SetupClient(configs)
+	if err != nil {
+		panic(err)
+	}
+}
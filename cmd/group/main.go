@@ -4,6 +4,7 @@ import (
 	"github.com/glide-im/glideim/im"
 	"github.com/glide-im/glideim/service"
 	"github.com/glide-im/glideim/service/dispatch"
+	"github.com/glide-im/glideim/service/gateway"
 	"github.com/glide-im/glideim/service/group_messaging"
 )
 
@@ -21,6 +22,11 @@ func main() {
 		panic(err)
 	}
 
+	err = gateway.SetupPresence(config)
+	if err != nil {
+		panic(err)
+	}
+
 	err = group_messaging.RunServer(config)
 	if err != nil {
 		panic(err)
@@ -8,6 +8,9 @@ import (
 	"github.com/glide-im/glideim/im/dao"
 	"github.com/glide-im/glideim/im/group"
 	"github.com/glide-im/glideim/im/messaging"
+	"github.com/glide-im/glideim/im/moderation"
+	"github.com/glide-im/glideim/im/push"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/pkg/logger"
 	"github.com/glide-im/glideim/pkg/rpc"
@@ -23,6 +26,18 @@ func main() {
 	}
 	db.Init()
 	dao.Init()
+	err = moderation.Init()
+	if err != nil {
+		panic(err)
+	}
+	err = webhook.Init()
+	if err != nil {
+		panic(err)
+	}
+	err = push.Init()
+	if err != nil {
+		panic(err)
+	}
 
 	var server conn.Server
 
@@ -30,7 +45,11 @@ func main() {
 		ReadTimeout:  60 * time.Second,
 		WriteTimeout: 60 * time.Second,
 	}
+	messaging.Init()
 	client.SetMessageHandler(messaging.HandleMessage)
+	go messaging.RunExpireSweeper()
+	go messaging.RunScheduler()
+	go webhook.Run()
 	server = conn.NewWsServer(op)
 
 	cm := client.NewDefaultManager()
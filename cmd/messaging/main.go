@@ -2,16 +2,36 @@ package main
 
 import (
 	"github.com/glide-im/glideim/im/dao"
+	"github.com/glide-im/glideim/im/messaging"
+	"github.com/glide-im/glideim/im/moderation"
+	"github.com/glide-im/glideim/im/push"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/service"
 	"github.com/glide-im/glideim/service/broker"
 	"github.com/glide-im/glideim/service/dispatch"
+	"github.com/glide-im/glideim/service/gateway"
 	"github.com/glide-im/glideim/service/messaging_service"
 )
 
 func main() {
 	db.Init()
 	dao.Init()
+	err := moderation.Init()
+	if err != nil {
+		panic(err)
+	}
+	err = webhook.Init()
+	if err != nil {
+		panic(err)
+	}
+	go webhook.Run()
+	go messaging.RunExpireSweeper()
+	go messaging.RunScheduler()
+	err = push.Init()
+	if err != nil {
+		panic(err)
+	}
 
 	config, err := service.GetConfig()
 	if err != nil {
@@ -23,11 +43,17 @@ func main() {
 		panic(err)
 	}
 
+	err = gateway.SetupPresence(config)
+	if err != nil {
+		panic(err)
+	}
+
 	err = broker.SetupClient(config)
 	if err != nil {
 		panic(err)
 	}
 
+	messaging.Init()
 	err = messaging_service.RunServer(config)
 
 	if err != nil {
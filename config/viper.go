@@ -8,6 +8,12 @@ var (
 	WsServer    *WsServerConf
 	ApiHttp     *ApiHttpConf
 	IMRpcServer *IMRpcServerConf
+	Messaging   = defaultMessagingConf()
+	IdGen       = defaultIdGenConf()
+	Moderation  = defaultModerationConf()
+	Webhook     = defaultWebhookConf()
+	ServerApi   = defaultServerApiConf()
+	Push        = defaultPushConf()
 )
 
 type WsServerConf struct {
@@ -29,6 +35,238 @@ type IMRpcServerConf struct {
 	EnableGroup bool
 }
 
+// MessagingConf 消息处理相关配置, 未配置的项使用默认值
+type MessagingConf struct {
+	// EditWindow 消息发出后允许编辑的时限, 单位秒
+	EditWindow int64
+	// GroupReadNotifyInterval 群消息已读人数变化后, 合并推送给发送者的间隔, 单位秒
+	GroupReadNotifyInterval int64
+	// GroupReadNotifyRange 每次推送已读人数时最多计算最近多少条群消息
+	GroupReadNotifyRange int64
+	// TypingInterval 同一发送者对同一会话发送正在输入事件的最小间隔, 单位秒
+	TypingInterval int64
+	// TypingTimeout 正在输入状态的过期时间, 超时未收到停止事件时由服务端发送停止事件, 单位秒
+	TypingTimeout int64
+	// TypingGroupMaxMembers 成员数不超过该值的群才转发正在输入事件
+	TypingGroupMaxMembers int
+	// ExpireNearTerm 存活时间不超过该值的消息使用时间轮定时删除, 其余由定期清理任务删除, 单位秒
+	ExpireNearTerm int64
+	// ExpireSweepInterval 过期消息定期清理的间隔, 单位秒
+	ExpireSweepInterval int64
+	// MaxMessageTTL 会话允许设置的最大消息存活时间, 单位秒
+	MaxMessageTTL int64
+	// ScheduleCheckInterval 检查到期定时消息的间隔, 单位秒
+	ScheduleCheckInterval int64
+	// MaxScheduleAhead 定时消息最多可以提前多久创建, 单位秒
+	MaxScheduleAhead int64
+	// MaxForwardMessages 一次最多转发的消息数量
+	MaxForwardMessages int
+	// SendIdempotentWindow 以 (发送者, 设备, cliSeq) 去重重复发送的时间窗口, 单位秒
+	SendIdempotentWindow int64
+	// ChatRecallWindow 单聊消息发出后允许发送者撤回的时限, 单位秒
+	ChatRecallWindow int64
+	// GroupRecallWindow 群消息发出后允许发送者撤回的时限, 单位秒
+	GroupRecallWindow int64
+	// GroupAdminRecallWindow 群管理员撤回消息的时限, 管理员可以撤回普通成员和自己的消息, 单位秒, 0 表示不限制
+	GroupAdminRecallWindow int64
+	// GroupSyncLimit 登录时每个群最多推送多少条错过的消息, 超过时只推送该群的未读摘要, 0 表示只推送摘要
+	GroupSyncLimit int64
+	// OfflineMaxMessages 每个用户最多保留的离线消息数量, 超过时删除最早的消息并记录错过的数量, 0 表示不限制
+	OfflineMaxMessages int64
+	// OfflineExpire 离线消息的保留时长, 过期后不再返回并由定期清理任务删除, 单位秒, 0 表示不过期
+	OfflineExpire int64
+	// ReactionEmojis 允许使用的表情回应, 为空时允许任意不超过 ReactionMaxLength 的表情
+	ReactionEmojis []string
+	// ReactionMaxLength 表情回应最多包含的字符数
+	ReactionMaxLength int
+}
+
+func defaultMessagingConf() *MessagingConf {
+	return &MessagingConf{
+		EditWindow:              60 * 15,
+		GroupReadNotifyInterval: 3,
+		GroupReadNotifyRange:    200,
+		TypingInterval:          2,
+		TypingTimeout:           8,
+		TypingGroupMaxMembers:   50,
+		ExpireNearTerm:          60 * 10,
+		ExpireSweepInterval:     30,
+		MaxMessageTTL:           60 * 60 * 24 * 7,
+		ScheduleCheckInterval:   1,
+		MaxScheduleAhead:        60 * 60 * 24 * 30,
+		MaxForwardMessages:      100,
+		SendIdempotentWindow:    60 * 5,
+		ChatRecallWindow:        60 * 2,
+		GroupRecallWindow:       60 * 2,
+		GroupAdminRecallWindow:  0,
+		GroupSyncLimit:          50,
+		OfflineMaxMessages:      1000,
+		OfflineExpire:           60 * 60 * 24 * 7,
+		ReactionEmojis:          []string{"👍", "👎", "❤️", "😂", "😮", "😢", "🎉", "🔥", "👏", "🙏"},
+		ReactionMaxLength:       8,
+	}
+}
+
+const (
+	IdGenModeRedis     = "redis"
+	IdGenModeSegment   = "segment"
+	IdGenModeSnowflake = "snowflake"
+)
+
+// IdGenConf ID 分配相关配置, 未配置的项使用默认值
+type IdGenConf struct {
+	// UidMode 用户 ID 的分配方式, segment 或 redis
+	UidMode string
+	// MidMode 消息 ID 的分配方式, segment, snowflake 或 redis
+	MidMode string
+	// SegmentStep 号段分配每次租用的 ID 数量
+	SegmentStep int64
+	// NodeId 雪花算法的节点 ID, 集群中每个节点需要不同, 0-1023
+	NodeId int64
+}
+
+func defaultIdGenConf() *IdGenConf {
+	return &IdGenConf{
+		UidMode:     IdGenModeSegment,
+		MidMode:     IdGenModeSegment,
+		SegmentStep: 1000,
+		NodeId:      0,
+	}
+}
+
+const (
+	ModerationActionReject = "reject"
+	ModerationActionMask   = "mask"
+	ModerationActionFlag   = "flag"
+)
+
+// ModerationConf 内容审核相关配置, 未配置的项使用默认值
+type ModerationConf struct {
+	// Enable 是否审核用户发送的单聊和群聊消息
+	Enable bool
+	// Dictionary 敏感词词典文件路径, 每行一个词, 可以用 "词,处理方式" 指定处理方式
+	Dictionary string
+	// DefaultAction 词典中未指定处理方式的词的处理方式, reject, mask 或 flag
+	DefaultAction string
+	// MaskChar 屏蔽敏感词使用的字符
+	MaskChar string
+	// ReloadInterval 检查词典文件是否修改的间隔, 修改后自动重新加载, 单位秒, 0 表示不检查
+	ReloadInterval int64
+}
+
+func defaultModerationConf() *ModerationConf {
+	return &ModerationConf{
+		Enable:         false,
+		Dictionary:     "",
+		DefaultAction:  ModerationActionMask,
+		MaskChar:       "*",
+		ReloadInterval: 10,
+	}
+}
+
+// WebhookConf 事件推送相关配置, 未配置的项使用默认值
+type WebhookConf struct {
+	// Enable 是否推送事件
+	Enable bool
+	// Admins 可以管理订阅和查看推送记录的用户 ID
+	Admins []int64
+	// Timeout 推送请求的超时时间, 单位秒
+	Timeout int64
+	// MaxAttempts 每个事件最多推送的次数, 用尽后进入死信列表
+	MaxAttempts int
+	// RetryBackoff 第一次重试的等待时间, 之后每次翻倍, 单位秒
+	RetryBackoff int64
+	// MaxRetryBackoff 重试等待时间的上限, 单位秒
+	MaxRetryBackoff int64
+	// CheckInterval 检查到期重试的间隔, 单位秒
+	CheckInterval int64
+	// RefreshInterval 从数据库刷新订阅的间隔, 单位秒
+	RefreshInterval int64
+	// AllowPrivateNetwork 是否允许推送到内网, 本机和链路本地地址, 仅在订阅地址都可信时开启, 机器人的推送地址始终不允许
+	AllowPrivateNetwork bool
+}
+
+func defaultWebhookConf() *WebhookConf {
+	return &WebhookConf{
+		Enable:              false,
+		Admins:              []int64{},
+		Timeout:             5,
+		MaxAttempts:         8,
+		RetryBackoff:        10,
+		MaxRetryBackoff:     60 * 60,
+		CheckInterval:       1,
+		RefreshInterval:     30,
+		AllowPrivateNetwork: false,
+	}
+}
+
+// ServerApiConf 服务端发送消息接口相关配置, 供业务后端以系统账号或机器人发送消息
+type ServerApiConf struct {
+	// Enable 是否开放服务端发送消息接口
+	Enable bool
+	// Keys 调用接口的密钥, HTTP 接口以 Authorization: Key <key> 认证, rpc 接口在请求元数据中携带
+	Keys []string
+	// MaxTargets 单次批量发送的最大接收者数量
+	MaxTargets int
+}
+
+func defaultServerApiConf() *ServerApiConf {
+	return &ServerApiConf{
+		Enable:     false,
+		Keys:       []string{},
+		MaxTargets: 1000,
+	}
+}
+
+// PushConf 离线推送相关配置, 未配置的平台不推送
+type PushConf struct {
+	// Enable 是否向离线用户推送通知
+	Enable bool
+	// CollapseWindow 同一会话在该时间内的多条消息合并为一条通知, 单位秒
+	CollapseWindow int64
+	// BadgeSessions 计算角标未读数时统计的最近会话数量
+	BadgeSessions int64
+	// Templates 通知内容模板, 键为消息类型, default 为未配置类型的模板
+	Templates map[string]string
+	// CollapseTemplate 合并多条消息的通知内容模板
+	CollapseTemplate string
+
+	// ApnsKeyFile APNs 认证密钥 .p8 文件
+	ApnsKeyFile    string
+	ApnsKeyId      string
+	ApnsTeamId     string
+	ApnsTopic      string
+	ApnsProduction bool
+	// FcmServerKey FCM 服务端密钥
+	FcmServerKey string
+	// WebPushPublicKey, WebPushPrivateKey VAPID 密钥, base64url 编码
+	WebPushPublicKey  string
+	WebPushPrivateKey string
+	// WebPushSubject VAPID 联系方式, mailto: 或 https: 地址
+	WebPushSubject string
+	// WebPushHosts 允许的浏览器推送服务域名, 以 . 开头时匹配其子域名, 不在列表中的推送地址不会注册和推送
+	WebPushHosts []string
+}
+
+func defaultPushConf() *PushConf {
+	return &PushConf{
+		Enable:         false,
+		CollapseWindow: 10,
+		BadgeSessions:  100,
+		Templates: map[string]string{
+			"default": "{{.Content}}",
+			"100":     "[聊天记录]",
+		},
+		CollapseTemplate: "{{.Count}} 条新消息",
+		WebPushHosts: []string{
+			"fcm.googleapis.com",
+			"updates.push.services.mozilla.com",
+			".notify.windows.com",
+			".push.apple.com",
+		},
+	}
+}
+
 type MySqlConf struct {
 	Host     string
 	Port     int
@@ -54,6 +292,75 @@ func Load() error {
 	viper.AddConfigPath("/etc/")
 	viper.AddConfigPath("$HOME/.config/")
 
+	d := defaultMessagingConf()
+	viper.SetDefault("Messaging.EditWindow", d.EditWindow)
+	viper.SetDefault("Messaging.GroupReadNotifyInterval", d.GroupReadNotifyInterval)
+	viper.SetDefault("Messaging.GroupReadNotifyRange", d.GroupReadNotifyRange)
+	viper.SetDefault("Messaging.TypingInterval", d.TypingInterval)
+	viper.SetDefault("Messaging.TypingTimeout", d.TypingTimeout)
+	viper.SetDefault("Messaging.TypingGroupMaxMembers", d.TypingGroupMaxMembers)
+	viper.SetDefault("Messaging.ExpireNearTerm", d.ExpireNearTerm)
+	viper.SetDefault("Messaging.ExpireSweepInterval", d.ExpireSweepInterval)
+	viper.SetDefault("Messaging.MaxMessageTTL", d.MaxMessageTTL)
+	viper.SetDefault("Messaging.ScheduleCheckInterval", d.ScheduleCheckInterval)
+	viper.SetDefault("Messaging.MaxScheduleAhead", d.MaxScheduleAhead)
+	viper.SetDefault("Messaging.MaxForwardMessages", d.MaxForwardMessages)
+	viper.SetDefault("Messaging.SendIdempotentWindow", d.SendIdempotentWindow)
+	viper.SetDefault("Messaging.ChatRecallWindow", d.ChatRecallWindow)
+	viper.SetDefault("Messaging.GroupRecallWindow", d.GroupRecallWindow)
+	viper.SetDefault("Messaging.GroupAdminRecallWindow", d.GroupAdminRecallWindow)
+	viper.SetDefault("Messaging.GroupSyncLimit", d.GroupSyncLimit)
+	viper.SetDefault("Messaging.OfflineMaxMessages", d.OfflineMaxMessages)
+	viper.SetDefault("Messaging.OfflineExpire", d.OfflineExpire)
+	viper.SetDefault("Messaging.ReactionEmojis", d.ReactionEmojis)
+	viper.SetDefault("Messaging.ReactionMaxLength", d.ReactionMaxLength)
+
+	ig := defaultIdGenConf()
+	viper.SetDefault("IdGen.UidMode", ig.UidMode)
+	viper.SetDefault("IdGen.MidMode", ig.MidMode)
+	viper.SetDefault("IdGen.SegmentStep", ig.SegmentStep)
+	viper.SetDefault("IdGen.NodeId", ig.NodeId)
+
+	md := defaultModerationConf()
+	viper.SetDefault("Moderation.Enable", md.Enable)
+	viper.SetDefault("Moderation.Dictionary", md.Dictionary)
+	viper.SetDefault("Moderation.DefaultAction", md.DefaultAction)
+	viper.SetDefault("Moderation.MaskChar", md.MaskChar)
+	viper.SetDefault("Moderation.ReloadInterval", md.ReloadInterval)
+
+	wh := defaultWebhookConf()
+	viper.SetDefault("Webhook.Enable", wh.Enable)
+	viper.SetDefault("Webhook.Admins", wh.Admins)
+	viper.SetDefault("Webhook.Timeout", wh.Timeout)
+	viper.SetDefault("Webhook.MaxAttempts", wh.MaxAttempts)
+	viper.SetDefault("Webhook.RetryBackoff", wh.RetryBackoff)
+	viper.SetDefault("Webhook.MaxRetryBackoff", wh.MaxRetryBackoff)
+	viper.SetDefault("Webhook.CheckInterval", wh.CheckInterval)
+	viper.SetDefault("Webhook.RefreshInterval", wh.RefreshInterval)
+	viper.SetDefault("Webhook.AllowPrivateNetwork", wh.AllowPrivateNetwork)
+
+	sa := defaultServerApiConf()
+	viper.SetDefault("ServerApi.Enable", sa.Enable)
+	viper.SetDefault("ServerApi.Keys", sa.Keys)
+	viper.SetDefault("ServerApi.MaxTargets", sa.MaxTargets)
+
+	ps := defaultPushConf()
+	viper.SetDefault("Push.Enable", ps.Enable)
+	viper.SetDefault("Push.CollapseWindow", ps.CollapseWindow)
+	viper.SetDefault("Push.BadgeSessions", ps.BadgeSessions)
+	viper.SetDefault("Push.Templates", ps.Templates)
+	viper.SetDefault("Push.CollapseTemplate", ps.CollapseTemplate)
+	viper.SetDefault("Push.ApnsKeyFile", ps.ApnsKeyFile)
+	viper.SetDefault("Push.ApnsKeyId", ps.ApnsKeyId)
+	viper.SetDefault("Push.ApnsTeamId", ps.ApnsTeamId)
+	viper.SetDefault("Push.ApnsTopic", ps.ApnsTopic)
+	viper.SetDefault("Push.ApnsProduction", ps.ApnsProduction)
+	viper.SetDefault("Push.FcmServerKey", ps.FcmServerKey)
+	viper.SetDefault("Push.WebPushPublicKey", ps.WebPushPublicKey)
+	viper.SetDefault("Push.WebPushPrivateKey", ps.WebPushPrivateKey)
+	viper.SetDefault("Push.WebPushSubject", ps.WebPushSubject)
+	viper.SetDefault("Push.WebPushHosts", ps.WebPushHosts)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		return err
@@ -64,6 +371,12 @@ func Load() error {
 		WsServer    *WsServerConf
 		ApiHttp     *ApiHttpConf
 		IMRpcServer *IMRpcServerConf
+		Messaging   *MessagingConf
+		IdGen       *IdGenConf
+		Moderation  *ModerationConf
+		Webhook     *WebhookConf
+		ServerApi   *ServerApiConf
+		Push        *PushConf
 	}{}
 
 	err = viper.Unmarshal(&c)
@@ -75,6 +388,12 @@ func Load() error {
 	WsServer = c.WsServer
 	ApiHttp = c.ApiHttp
 	IMRpcServer = c.IMRpcServer
+	Messaging = c.Messaging
+	IdGen = c.IdGen
+	Moderation = c.Moderation
+	Webhook = c.Webhook
+	ServerApi = c.ServerApi
+	Push = c.Push
 
 	return err
 }
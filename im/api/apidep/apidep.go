@@ -4,6 +4,7 @@ import (
 	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/group"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/messaging"
 	"github.com/glide-im/glideim/pkg/logger"
 )
 
@@ -24,6 +25,19 @@ func SendMessageIfOnline(uid int64, device int64, m *message.Message) {
 	SendMessage(uid, device, m)
 }
 
+// ErrMessageRejected 消息被内容审核拒绝
+var ErrMessageRejected = messaging.ErrMessageRejected
+
+// SendMessages 服务端以系统账号或机器人批量发送消息
+func SendMessages(req *messaging.SendRequest) ([]*messaging.SendResult, error) {
+	return messaging.SendMessages(req)
+}
+
+// SendMessageAs 以 from 的身份发送单聊或群聊消息, 消息的保存, 确认和投递与客户端发送一致
+func SendMessageAs(from int64, group bool, msg *message.ChatMessage) error {
+	return messaging.SendMessageAs(from, group, msg)
+}
+
 type ClientManagerInterface interface {
 	SignIn(oldUid int64, uid int64, device int64) error
 	Logout(uid int64, device int64) error
@@ -57,6 +71,7 @@ type GroupManagerInterface interface {
 	CreateGroup(gid int64) error
 	DissolveGroup(gid int64) error
 	MuteGroup(gid int64, mute bool) error
+	UpdateGroupMsgTTL(gid int64, ttl int64) error
 	UpdateMember(gid int64, uid int64, flag int64) error
 	DispatchNotifyMessage(gid int64, message *message.GroupNotify) error
 }
@@ -136,6 +151,10 @@ func (g *groupInterface) MuteGroup(gid int64, mute bool) error {
 	return group.UpdateGroup(gid, group.Update{Flag: int64(f)})
 }
 
+func (g *groupInterface) UpdateGroupMsgTTL(gid int64, ttl int64) error {
+	return group.UpdateGroup(gid, group.Update{Flag: group.FlagGroupMsgTTL, Extra: ttl})
+}
+
 func (g *groupInterface) DispatchNotifyMessage(gid int64, message *message.GroupNotify) error {
 	return group.DispatchNotifyMessage(gid, message)
 }
@@ -70,6 +70,11 @@ func (m *MockGroupManager) MuteGroup(gid int64, mute bool) error {
 	return nil
 }
 
+func (m *MockGroupManager) UpdateGroupMsgTTL(gid int64, ttl int64) error {
+	logger.D("UpdateGroupMsgTTL, gid=%d, ttl=%d", gid, ttl)
+	return nil
+}
+
 func (m *MockGroupManager) DispatchNotifyMessage(gid int64, message *message.GroupNotify) error {
 	logger.D("DispatchNotifyMessage, gid=%d, message=%v", gid, message)
 	return nil
@@ -7,8 +7,11 @@ import (
 	"github.com/glide-im/glideim/im/api/router"
 	"github.com/glide-im/glideim/im/auth"
 	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/pushdao"
 	"github.com/glide-im/glideim/im/dao/userdao"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/webhook"
+	"github.com/glide-im/glideim/pkg/logger"
 	"math/rand"
 	"time"
 )
@@ -100,6 +103,7 @@ func (*AuthApi) SignIn(ctx *route.Context, request *SignInRequest) error {
 	ctx.Uid = uid
 	ctx.Device = request.Device
 	ctx.Response(resp)
+	webhook.Publish(webhook.EventUserSignIn, &webhook.UserEvent{Uid: uid, Device: request.Device})
 	return nil
 }
 
@@ -149,6 +153,7 @@ func (*AuthApi) GuestRegister(ctx *route.Context, req *GuestRegisterRequest) err
 	ctx.Uid = uid
 	ctx.Device = 3
 	ctx.Response(resp)
+	webhook.Publish(webhook.EventUserRegistered, &webhook.UserEvent{Uid: uid, Device: 3})
 	return nil
 }
 
@@ -166,6 +171,7 @@ func (*AuthApi) Register(ctx *route.Context, req *RegisterRequest) error {
 		return comm.NewDbErr(err)
 	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	webhook.Publish(webhook.EventUserRegistered, &webhook.UserEvent{Uid: u.Uid})
 	return err
 }
 
@@ -174,8 +180,14 @@ func (a *AuthApi) Logout(ctx *route.Context) error {
 	if err != nil {
 		return comm.NewDbErr(err)
 	}
+	// 登出的设备不再接收离线推送
+	err = pushdao.Dao.RemoveToken(ctx.Uid, ctx.Device)
+	if err != nil {
+		logger.E("remove push token error %v", err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
 	apidep.ClientInterface.Logout(ctx.Uid, ctx.Device)
+	webhook.Publish(webhook.EventUserSignOut, &webhook.UserEvent{Uid: ctx.Uid, Device: ctx.Device})
 	return nil
 }
 
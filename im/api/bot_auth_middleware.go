@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/glide-im/glideim/im/auth"
+	"github.com/glide-im/glideim/im/bot"
+	"net/http"
+	"strings"
+)
+
+const botAuthPrefix = "Bot "
+
+// botAuthMiddleware 机器人接口以 Authorization: Bot <token> 认证, 认证后的 uid 为机器人 uid
+func botAuthMiddleware(context *gin.Context) {
+	authHeader := context.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, botAuthPrefix) {
+		context.Status(http.StatusUnauthorized)
+		context.Abort()
+		return
+	}
+	b, err := bot.Authenticate(strings.TrimPrefix(authHeader, botAuthPrefix))
+	if err != nil {
+		context.Status(http.StatusUnauthorized)
+		context.Abort()
+		return
+	}
+	context.Set(CtxKeyAuthInfo, &auth.AuthInfo{Uid: b.Uid, Device: bot.Device})
+	context.Next()
+}
@@ -0,0 +1,286 @@
+package bots
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/api/apidep"
+	"github.com/glide-im/glideim/im/api/comm"
+	"github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/bot"
+	"github.com/glide-im/glideim/im/dao/botdao"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/uid"
+	"github.com/glide-im/glideim/im/group"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/glide-im/glideim/pkg/netguard"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	botNameMaxLen = 32
+
+	updatesDefaultLimit = 100
+	updatesMaxLimit     = 100
+	// updatesMaxTimeout 长轮询最长等待时间, 单位秒
+	updatesMaxTimeout = 50
+)
+
+// BotApi 用户管理自己的机器人, 以及机器人以 token 认证后拉取和发送消息
+type BotApi struct {
+}
+
+func (*BotApi) CreateBot(ctx *route.Context, request *CreateBotRequest) error {
+	if err := checkBot(request.Name, request.WebhookUrl); err != nil {
+		return err
+	}
+	botUid := uid.GenSysUid()
+	if !uid.IsSystemId(botUid) {
+		return errUidExhausted
+	}
+	now := time.Now().Unix()
+	b := &botdao.Bot{
+		Uid:      botUid,
+		Owner:    ctx.Uid,
+		Name:     request.Name,
+		Avatar:   request.Avatar,
+		CreateAt: now,
+		UpdateAt: now,
+	}
+	token, hash := bot.NewToken(botUid)
+	b.TokenHash = hash
+	secret, err := bot.SetWebhook(b, request.WebhookUrl)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	err = botdao.Dao.CreateBot(b)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	resp := bot2Response(b, request.WebhookUrl)
+	resp.Token = token
+	resp.WebhookSecret = secret
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+func (*BotApi) UpdateBot(ctx *route.Context, request *UpdateBotRequest) error {
+	if err := checkBot(request.Name, request.WebhookUrl); err != nil {
+		return err
+	}
+	b, err := getOwnBot(ctx.Uid, request.Uid)
+	if err != nil {
+		return err
+	}
+	b.Name = request.Name
+	b.Avatar = request.Avatar
+	secret, err := bot.SetWebhook(b, request.WebhookUrl)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	err = botdao.Dao.UpdateBot(b)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	bot.Invalidate(b.Uid)
+	resp := bot2Response(b, request.WebhookUrl)
+	resp.WebhookSecret = secret
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// ResetToken 重置机器人 token, 旧 token 立即失效
+func (*BotApi) ResetToken(ctx *route.Context, request *BotRequest) error {
+	b, err := getOwnBot(ctx.Uid, request.Uid)
+	if err != nil {
+		return err
+	}
+	token, hash := bot.NewToken(b.Uid)
+	err = botdao.Dao.UpdateToken(b.Uid, hash)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	resp, err := getBotResponse(b)
+	if err != nil {
+		return err
+	}
+	resp.Token = token
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// DeleteBot 删除机器人, 同时删除推送订阅并将机器人移出所有群
+func (*BotApi) DeleteBot(ctx *route.Context, request *BotRequest) error {
+	b, err := getOwnBot(ctx.Uid, request.Uid)
+	if err != nil {
+		return err
+	}
+	if _, err = bot.SetWebhook(b, ""); err != nil {
+		return comm.NewDbErr(err)
+	}
+	gids, err := groupdao.Dao.GetMemberGroups(b.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	for _, gid := range gids {
+		err = groupdao.Dao.RemoveMember(gid, b.Uid)
+		if err != nil && err != common.ErrNoRecordFound {
+			return comm.NewDbErr(err)
+		}
+		err = apidep.GroupInterface.UpdateMember(gid, b.Uid, group.FlagMemberBotRemoved)
+		if err != nil {
+			logger.E("remove bot %d from group %d error: %v", b.Uid, gid, err)
+		}
+	}
+	err = botdao.Dao.DeleteBot(b.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	bot.Invalidate(b.Uid)
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func (*BotApi) GetBots(ctx *route.Context) error {
+	bs, err := botdao.Dao.GetBotsByOwner(ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*BotResponse{}
+	for _, b := range bs {
+		r, err := getBotResponse(b)
+		if err != nil {
+			return err
+		}
+		resp = append(resp, r)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// GetMe 机器人获取自己的信息, 以机器人 token 认证
+func (*BotApi) GetMe(ctx *route.Context) error {
+	b, err := bot.GetBot(ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if b == nil {
+		return errBotNotExist
+	}
+	resp, err := getBotResponse(b)
+	if err != nil {
+		return err
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// GetUpdates 机器人长轮询拉取消息, 以机器人 token 认证
+func (*BotApi) GetUpdates(ctx *route.Context, request *GetUpdatesRequest) error {
+	limit := request.Limit
+	if limit <= 0 || limit > updatesMaxLimit {
+		limit = updatesDefaultLimit
+	}
+	timeout := request.Timeout
+	if timeout < 0 {
+		timeout = 0
+	}
+	if timeout > updatesMaxTimeout {
+		timeout = updatesMaxTimeout
+	}
+	us, err := bot.GetUpdates(ctx.Uid, request.Offset, limit, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, &GetUpdatesResponse{Updates: us}))
+	return nil
+}
+
+// SendMessage 机器人发送单聊或群聊消息, 以机器人 token 认证, 群聊时机器人需要是群成员,
+// 单聊时用户需要添加机器人为联系人或者先给机器人发送过消息
+func (*BotApi) SendMessage(ctx *route.Context, request *SendMessageRequest) error {
+	if request.Content == "" {
+		return errInvalidMessage
+	}
+	if request.Group {
+		isMember, err := groupdao.Dao.HasMember(request.To, ctx.Uid)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		if !isMember {
+			return errInvalidMessage
+		}
+	} else {
+		if !uid.IsUserId(request.To) {
+			return errInvalidMessage
+		}
+		ok, err := bot.CanMessage(ctx.Uid, request.To)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		if !ok {
+			return errNoConversation
+		}
+	}
+	cm := message.NewChatMessage(0, 0, ctx.Uid, request.To, request.Type, request.Content, time.Now().Unix())
+	err := apidep.SendMessageAs(ctx.Uid, request.Group, &cm)
+	if err == apidep.ErrMessageRejected {
+		return errMessageRejected
+	}
+	if err != nil {
+		return comm.NewUnexpectedErr("send message failed", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, &SendMessageResponse{Mid: cm.Mid}))
+	return nil
+}
+
+func checkBot(name string, webhookUrl string) error {
+	if name == "" || utf8.RuneCountInString(name) > botNameMaxLen {
+		return errInvalidName
+	}
+	if webhookUrl == "" {
+		return nil
+	}
+	if !config.Webhook.Enable {
+		return errWebhookDisabled
+	}
+	// 推送地址由普通用户设置, 不允许内网地址, 避免服务端向内网发送请求
+	if err := netguard.CheckUrl(webhookUrl); err != nil {
+		return errInvalidUrl
+	}
+	return nil
+}
+
+func getOwnBot(owner int64, botUid int64) (*botdao.Bot, error) {
+	b, err := botdao.Dao.GetBot(botUid)
+	if err == common.ErrNoRecordFound {
+		return nil, errBotNotExist
+	}
+	if err != nil {
+		return nil, comm.NewDbErr(err)
+	}
+	if b.Owner != owner {
+		return nil, errBotNotExist
+	}
+	return b, nil
+}
+
+func getBotResponse(b *botdao.Bot) (*BotResponse, error) {
+	u, err := bot.GetWebhookUrl(b)
+	if err != nil {
+		return nil, comm.NewDbErr(err)
+	}
+	return bot2Response(b, u), nil
+}
+
+func bot2Response(b *botdao.Bot, webhookUrl string) *BotResponse {
+	return &BotResponse{
+		Uid:        b.Uid,
+		Name:       b.Name,
+		Avatar:     b.Avatar,
+		WebhookUrl: webhookUrl,
+		CreateAt:   b.CreateAt,
+	}
+}
@@ -0,0 +1,14 @@
+package bots
+
+import "github.com/glide-im/glideim/im/api/comm"
+
+var (
+	errBotNotExist     = comm.NewApiBizError(5001, "bot not exist")
+	errInvalidName     = comm.NewApiBizError(5002, "invalid bot name")
+	errInvalidUrl      = comm.NewApiBizError(5003, "invalid webhook url")
+	errWebhookDisabled = comm.NewApiBizError(5004, "webhook is disabled")
+	errInvalidMessage  = comm.NewApiBizError(5005, "invalid message")
+	errUidExhausted    = comm.NewApiBizError(5006, "bot uid exhausted")
+	errNoConversation  = comm.NewApiBizError(5007, "the user has not started a conversation with the bot")
+	errMessageRejected = comm.NewApiBizError(5008, "message rejected by moderation")
+)
@@ -0,0 +1,57 @@
+package bots
+
+import "github.com/glide-im/glideim/im/bot"
+
+type CreateBotRequest struct {
+	Name   string
+	Avatar string
+	// WebhookUrl 接收消息的推送地址, 为空时由机器人长轮询拉取消息
+	WebhookUrl string
+}
+
+type UpdateBotRequest struct {
+	Uid        int64
+	Name       string
+	Avatar     string
+	WebhookUrl string
+}
+
+type BotRequest struct {
+	Uid int64
+}
+
+type BotResponse struct {
+	Uid        int64
+	Name       string
+	Avatar     string
+	WebhookUrl string
+	// Token 仅在创建和重置时返回
+	Token string `json:",omitempty"`
+	// WebhookSecret 推送签名密钥, 仅在新建推送订阅时返回
+	WebhookSecret string `json:",omitempty"`
+	CreateAt      int64
+}
+
+type GetUpdatesRequest struct {
+	// Offset 返回 ID 不小于 Offset 的消息, 并确认 ID 小于 Offset 的消息
+	Offset int64
+	Limit  int
+	// Timeout 没有消息时的最长等待时间, 单位秒, 0 表示立即返回
+	Timeout int64
+}
+
+type GetUpdatesResponse struct {
+	Updates []*bot.Update
+}
+
+type SendMessageRequest struct {
+	// To 接收者 uid, Group 为 true 时为群 ID
+	To      int64
+	Group   bool
+	Type    int32
+	Content string
+}
+
+type SendMessageResponse struct {
+	Mid int64
+}
@@ -0,0 +1,136 @@
+package groups
+
+import (
+	"github.com/glide-im/glideim/im/api/apidep"
+	"github.com/glide-im/glideim/im/api/comm"
+	"github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/bot"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/group"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+)
+
+// AddBot 群主和管理员将机器人添加到群, 机器人默认只收到 @ 它的消息
+func (m *GroupApi) AddBot(ctx *route.Context, request *GroupBotRequest) error {
+	if err := checkGroupAdmin(request.Gid, ctx.Uid); err != nil {
+		return err
+	}
+	b, err := bot.GetBot(request.BotUid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if b == nil {
+		return ErrBotNotExist
+	}
+	err = addGroupMemberDb(request.Gid, b.Uid, groupdao.GroupMemberTypeBot)
+	if err != nil {
+		return err
+	}
+	if request.ReadAll {
+		err = groupdao.Dao.UpdateMemberFlag(request.Gid, b.Uid, groupdao.GroupMemberFlagBotReadAll)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+	}
+	err = apidep.GroupInterface.UpdateMember(request.Gid, b.Uid, botMemberFlag(request.ReadAll))
+	if err != nil {
+		return comm.NewUnexpectedErr("add group bot failed", err)
+	}
+	err = dispatchGroupNotify(request.Gid, message.GroupNotifyTypeMemberAdded, b.Uid)
+	if err != nil {
+		logger.E("notify add group bot error: %v", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+// UpdateBot 群主和管理员修改机器人是否可以收到群内所有消息
+func (m *GroupApi) UpdateBot(ctx *route.Context, request *GroupBotRequest) error {
+	if err := checkGroupAdmin(request.Gid, ctx.Uid); err != nil {
+		return err
+	}
+	if err := checkGroupBot(request.Gid, request.BotUid); err != nil {
+		return err
+	}
+	flag := groupdao.GroupFlagDefault
+	if request.ReadAll {
+		flag = groupdao.GroupMemberFlagBotReadAll
+	}
+	current, err := groupdao.Dao.GetMemberFlag(request.Gid, request.BotUid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if current != int64(flag) {
+		err = groupdao.Dao.UpdateMemberFlag(request.Gid, request.BotUid, flag)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+	}
+	err = apidep.GroupInterface.UpdateMember(request.Gid, request.BotUid, botMemberFlag(request.ReadAll))
+	if err != nil {
+		return comm.NewUnexpectedErr("update group bot failed", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+// RemoveBot 群主和管理员将机器人移出群
+func (m *GroupApi) RemoveBot(ctx *route.Context, request *GroupBotRequest) error {
+	if err := checkGroupAdmin(request.Gid, ctx.Uid); err != nil {
+		return err
+	}
+	if err := checkGroupBot(request.Gid, request.BotUid); err != nil {
+		return err
+	}
+	err := groupdao.Dao.RemoveMember(request.Gid, request.BotUid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	err = apidep.GroupInterface.UpdateMember(request.Gid, request.BotUid, group.FlagMemberBotRemoved)
+	if err != nil {
+		return comm.NewUnexpectedErr("remove group bot failed", err)
+	}
+	err = dispatchGroupNotify(request.Gid, message.GroupNotifyTypeMemberRemoved, request.BotUid)
+	if err != nil {
+		logger.E("notify remove group bot error: %v", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func checkGroupAdmin(gid int64, uid int64) error {
+	typ, err := groupdao.Dao.GetMemberType(gid, uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if typ != groupdao.GroupMemberTypeAdmin && typ != groupdao.GroupMemberTypeOwner {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+func checkGroupBot(gid int64, botUid int64) error {
+	isMember, err := groupdao.Dao.HasMember(gid, botUid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if !isMember {
+		return ErrBotNotExist
+	}
+	typ, err := groupdao.Dao.GetMemberType(gid, botUid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if typ != groupdao.GroupMemberTypeBot {
+		return ErrBotNotExist
+	}
+	return nil
+}
+
+func botMemberFlag(readAll bool) int64 {
+	if readAll {
+		return group.FlagMemberBot | group.FlagMemberBotReadAll
+	}
+	return group.FlagMemberBot
+}
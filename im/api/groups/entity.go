@@ -18,6 +18,12 @@ type InviteGroupMessage struct {
 	Gid int64
 }
 
+type GroupMsgTTLRequest struct {
+	Gid int64
+	// TTL 群消息存活时间, 单位秒, 0 表示关闭
+	TTL int64
+}
+
 type CreateGroupRequest struct {
 	Name string
 }
@@ -60,3 +66,10 @@ type RemoveMemberRequest struct {
 	Gid int64
 	Uid []int64
 }
+
+type GroupBotRequest struct {
+	Gid    int64
+	BotUid int64
+	// ReadAll 机器人可以收到群内所有消息, 否则只收到 @ 它的消息
+	ReadAll bool
+}
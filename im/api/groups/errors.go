@@ -5,4 +5,7 @@ import "github.com/glide-im/glideim/im/api/comm"
 var (
 	ErrGroupNotExit       = comm.NewApiBizError(3001, "ErrGroupNotExit")
 	ErrMemberAlreadyExist = comm.NewApiBizError(3002, "ErrMemberAlreadyExist")
+	ErrPermissionDenied   = comm.NewApiBizError(3003, "ErrPermissionDenied")
+	ErrInvalidMsgTTL      = comm.NewApiBizError(3004, "ErrInvalidMsgTTL")
+	ErrBotNotExist        = comm.NewApiBizError(3005, "ErrBotNotExist")
 )
@@ -1,6 +1,7 @@
 package groups
 
 import (
+	"github.com/glide-im/glideim/config"
 	"github.com/glide-im/glideim/im/api/apidep"
 	"github.com/glide-im/glideim/im/api/comm"
 	"github.com/glide-im/glideim/im/api/router"
@@ -10,6 +11,7 @@ import (
 	"github.com/glide-im/glideim/im/dao/userdao"
 	"github.com/glide-im/glideim/im/group"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/logger"
 	"time"
 )
@@ -55,6 +57,7 @@ func (m *GroupApi) CreateGroup(ctx *route.Context, request *CreateGroupRequest)
 	if err != nil {
 		return comm.NewUnexpectedErr("create group failed", err)
 	}
+	webhook.Publish(webhook.EventGroupCreated, &webhook.GroupEvent{Gid: dbGroup.Gid, Uid: ctx.Uid})
 	//n := message.NewMessage(0, comm.ActionInviteToGroup, InviteGroupMessage{Gid: dbGroup.Gid})
 	//for _, uid := range request.Member {
 	//	apidep.SendMessageIfOnline(uid, 0, n)
@@ -126,6 +129,30 @@ func (m *GroupApi) RemoveMember(ctx *route.Context, request *RemoveMemberRequest
 	return nil
 }
 
+// SetGroupMsgTTL 设置群消息存活时间, 仅群主和管理员可以设置, 只对之后发送的消息生效
+func (m *GroupApi) SetGroupMsgTTL(ctx *route.Context, request *GroupMsgTTLRequest) error {
+	if request.TTL < 0 || request.TTL > config.Messaging.MaxMessageTTL {
+		return ErrInvalidMsgTTL
+	}
+	typ, err := groupdao.Dao.GetMemberType(request.Gid, ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if typ != groupdao.GroupMemberTypeAdmin && typ != groupdao.GroupMemberTypeOwner {
+		return ErrPermissionDenied
+	}
+	err = groupdao.Dao.UpdateGroupMsgTTL(request.Gid, request.TTL)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	err = apidep.GroupInterface.UpdateGroupMsgTTL(request.Gid, request.TTL)
+	if err != nil {
+		logger.E("update group message ttl error: %v", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
 func (m *GroupApi) AddGroupMember(ctx *route.Context, request *AddMemberRequest) error {
 	for _, uid := range request.Uid {
 		err := addGroupMemberDb(request.Gid, uid, groupdao.GroupMemberNormal)
@@ -234,6 +261,12 @@ func dispatchGroupNotify(gid int64, typ int64, uid int64) error {
 		logger.E("get message id error:%v", err)
 		return err
 	}
+	switch typ {
+	case message.GroupNotifyTypeMemberAdded:
+		webhook.Publish(webhook.EventGroupMemberAdded, &webhook.GroupEvent{Gid: gid, Uid: uid})
+	case message.GroupNotifyTypeMemberRemoved:
+		webhook.Publish(webhook.EventGroupMemberRemoved, &webhook.GroupEvent{Gid: gid, Uid: uid})
+	}
 	n := message.NewGroupNotifyAdded([]int64{uid})
 	notify := message.NewGroupNotify(id, gid, 0, typ, time.Now().Unix(), &n)
 	return apidep.GroupInterface.DispatchNotifyMessage(gid, notify)
@@ -1,6 +1,7 @@
 package msg
 
 import (
+	"github.com/glide-im/glideim/config"
 	"github.com/glide-im/glideim/im/api/comm"
 	"github.com/glide-im/glideim/im/api/router"
 	"github.com/glide-im/glideim/im/dao/msgdao"
@@ -10,6 +11,11 @@ import (
 	"time"
 )
 
+const (
+	offlineDefaultLimit = 50
+	offlineMaxLimit     = 200
+)
+
 type ChatMsgApi struct{}
 
 //goland:noinspection GoPreferNilSlice
@@ -22,6 +28,9 @@ func (*ChatMsgApi) GetRecentChatMessage(ctx *route.Context, request *RecentChatM
 	for _, m := range ms {
 		msr = append(msr, messageModel2MessageResponse(m))
 	}
+	if err = fillChatMessageReactions(msr); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, msr))
 	return nil
 }
@@ -40,6 +49,9 @@ func (*ChatMsgApi) GetChatMessageHistory(ctx *route.Context, request *ChatHistor
 	for _, m := range ms {
 		msr = append(msr, messageModel2MessageResponse(m))
 	}
+	if err = fillChatMessageReactions(msr); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, msr))
 	return nil
 }
@@ -54,6 +66,9 @@ func (*ChatMsgApi) GetRecentMessage(ctx *route.Context) error {
 	for _, m := range messages {
 		msr = append(msr, messageModel2MessageResponse(m))
 	}
+	if err = fillChatMessageReactions(msr); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, msr))
 	return nil
 }
@@ -73,6 +88,9 @@ func (*ChatMsgApi) GetRecentMessageByUser(ctx *route.Context, request *RecentMes
 		for _, m := range ms {
 			msr = append(msr, messageModel2MessageResponse(m))
 		}
+		if err = fillChatMessageReactions(msr); err != nil {
+			logger.E("GetRecentMessageByUser DB error %v", err)
+		}
 		resp = append(resp, RecentMessagesResponse{
 			Uid:      i,
 			Messages: msr,
@@ -86,7 +104,7 @@ func (*ChatMsgApi) GetRecentMessageByUser(ctx *route.Context, request *RecentMes
 }
 
 func (*ChatMsgApi) AckOfflineMessage(ctx *route.Context, request *AckOfflineMessageRequest) error {
-	err := msgdao.DelOfflineMessage(ctx.Uid, request.Mid)
+	err := msgdao.ChatMsgDaoImpl.AckOfflineMessage(ctx.Uid, request.Cursor)
 	if err != nil {
 		return comm.NewDbErr(err)
 	}
@@ -94,26 +112,56 @@ func (*ChatMsgApi) AckOfflineMessage(ctx *route.Context, request *AckOfflineMess
 }
 
 //goland:noinspection GoPreferNilSlice
-func (*ChatMsgApi) GetOfflineMessage(ctx *route.Context) error {
-	oms, err := msgdao.GetOfflineMessage(ctx.Uid)
+func (*ChatMsgApi) GetOfflineMessage(ctx *route.Context, request *OfflineMessageRequest) error {
+	limit := request.Limit
+	if limit <= 0 {
+		limit = offlineDefaultLimit
+	}
+	if limit > offlineMaxLimit {
+		limit = offlineMaxLimit
+	}
+	var since int64 = 0
+	if config.Messaging.OfflineExpire > 0 {
+		since = time.Now().Unix() - config.Messaging.OfflineExpire
+	}
+	oms, err := msgdao.GetOfflineMessage(ctx.Uid, request.Cursor, since, limit)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	missed, err := msgdao.ChatMsgDaoImpl.GetOfflineMissed(ctx.Uid)
 	if err != nil {
 		return comm.NewDbErr(err)
 	}
+	cursor := request.Cursor
 	var mid = []int64{}
 	for _, m := range oms {
 		mid = append(mid, m.MID)
+		cursor = m.ID
+	}
+	qms, err := msgdao.ChatMsgDaoImpl.GetVisibleChatMessages(ctx.Uid, mid...)
+	if err != nil {
+		return comm.NewDbErr(err)
 	}
-	qms, err := msgdao.GetChatMessage(mid...)
 	var ms = []*MessageResponse{}
 	for _, m := range qms {
 		ms = append(ms, messageModel2MessageResponse(m))
 	}
-	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ms))
+	if err = fillChatMessageReactions(ms); err != nil {
+		return comm.NewDbErr(err)
+	}
+	resp := &OfflineMessageResponse{
+		Messages: ms,
+		Cursor:   cursor,
+		More:     len(oms) == limit,
+		Missed:   missed,
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
 	return nil
 }
 
+// messageModel2MessageResponse 转换单聊消息, 已撤回的消息不返回原内容
 func messageModel2MessageResponse(m *msgdao.ChatMessage) *MessageResponse {
-	return &MessageResponse{
+	r := &MessageResponse{
 		Mid:      m.MID,
 		CliSeq:   m.CliSeq,
 		From:     m.From,
@@ -123,5 +171,12 @@ func messageModel2MessageResponse(m *msgdao.ChatMessage) *MessageResponse {
 		CreateAt: m.CreateAt,
 		Content:  m.Content,
 		Status:   m.Status,
+		Edited:   m.EditAt > 0,
+		EditAt:   m.EditAt,
+		ExpireAt: m.ExpireAt,
+	}
+	if m.Status == msgdao.ChatMessageStatusRecalled {
+		r.Content = ""
 	}
+	return r
 }
@@ -4,4 +4,10 @@ import "github.com/glide-im/glideim/im/api/comm"
 
 var (
 	errRecentMsgLoadFailed = comm.NewApiBizError(3001, "message load failed")
+	errNotGroupMember      = comm.NewApiBizError(3002, "not a group member")
+	errMessageNotExist     = comm.NewApiBizError(3003, "message not exist")
+	errInvalidTTL          = comm.NewApiBizError(3004, "invalid message ttl")
+	errInvalidScheduleTime = comm.NewApiBizError(3005, "invalid schedule time")
+	errScheduledNotExist   = comm.NewApiBizError(3006, "scheduled message not exist or already sent")
+	errInvalidKeyword      = comm.NewApiBizError(3007, "invalid search keyword")
 )
@@ -1,6 +1,9 @@
 package msg
 
-import "github.com/glide-im/glideim/im/dao/msgdao"
+import (
+	"encoding/json"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+)
 
 type MessageResponse struct {
 	Mid      int64
@@ -12,6 +15,11 @@ type MessageResponse struct {
 	CreateAt int64
 	Content  string
 	Status   int
+	Edited   bool
+	EditAt   int64
+	ExpireAt int64
+
+	Reactions []*ReactionResponse
 }
 
 type GroupMessageResponse struct {
@@ -24,6 +32,17 @@ type GroupMessageResponse struct {
 	Content  string
 	Status   int
 	RecallBy int64
+	Edited   bool
+	EditAt   int64
+	ExpireAt int64
+
+	Reactions []*ReactionResponse
+}
+
+type ReactionResponse struct {
+	Emoji string
+	Count int
+	Uid   []int64
 }
 
 type GroupMessageStateResponse struct {
@@ -32,20 +51,37 @@ type GroupMessageStateResponse struct {
 
 type ReadMessageRequest struct {
 	To int64
+	// Mid 已读的最后一条消息 ID, 为 0 时表示读到会话最后一条消息
+	Mid int64
+}
+
+type ReadMessageResponse struct {
+	To  int64
+	Mid int64
 }
 
 type SessionRequest struct {
 	To int64
 }
 
+type SessionTTLRequest struct {
+	To int64
+	// TTL 会话消息存活时间, 单位秒, 0 表示关闭
+	TTL int64
+}
+
 type SessionResponse struct {
-	Uid1     int64
-	Uid2     int64
-	To       int64
-	Unread   int64
-	LastMid  int64
-	UpdateAt int64
-	CreateAt int64
+	Uid1   int64
+	Uid2   int64
+	To     int64
+	Unread int64
+	// ReadMid 自己已读到的消息 ID, PeerReadMid 对方已读到的消息 ID
+	ReadMid     int64
+	PeerReadMid int64
+	LastMid     int64
+	TTL         int64
+	UpdateAt    int64
+	CreateAt    int64
 }
 
 type RecentChatMessageRequest struct {
@@ -66,8 +102,24 @@ type ChatHistoryRequest struct {
 	BeforeMid int64
 }
 
+type OfflineMessageRequest struct {
+	// Cursor 上一页返回的游标, 第一页为 0
+	Cursor int64
+	Limit  int
+}
+
+type OfflineMessageResponse struct {
+	Messages []*MessageResponse
+	// Cursor 本页最后一条离线消息的游标, 用于获取下一页和确认
+	Cursor int64
+	More   bool
+	// Missed 离线消息超过上限而被删除的最早消息数量, 确认后清零
+	Missed int64
+}
+
 type AckOfflineMessageRequest struct {
-	Mid []int64
+	// Cursor 确认该游标及之前的全部离线消息
+	Cursor int64
 }
 
 type GroupMessageRequest struct {
@@ -87,6 +139,103 @@ type GroupMsgStateRequest struct {
 	Gid int64
 }
 
+type GroupMsgReadRequest struct {
+	Gid int64
+	Seq int64
+}
+
+type GroupMsgReadResponse struct {
+	Gid       int64
+	Seq       int64
+	Mid       int64
+	ReadCount int64
+	// Read 已读的成员, Unread 未读的成员, 均不包含消息发送者
+	Read   []int64
+	Unread []int64
+}
+
+type ScheduledMessageRequest struct {
+	// To 单聊为接收者 ID, 群聊为群 ID
+	To      int64
+	Group   bool
+	Type    int32
+	Content string
+	// SendAt 计划发送时间
+	SendAt int64
+}
+
+type ScheduledMessageResponse struct {
+	ID       int64
+	To       int64
+	Group    bool
+	Type     int32
+	Content  string
+	SendAt   int64
+	CreateAt int64
+}
+
+type CancelScheduledMessageRequest struct {
+	ID int64
+}
+
 type MessageIDResponse struct {
 	Mid int64
 }
+
+type InboxSyncRequest struct {
+	// Seq 已同步的最大收件箱 seq, 返回该 seq 之后的事件
+	Seq   int64
+	Limit int
+}
+
+type InboxEventResponse struct {
+	Seq    int64
+	Action string
+	Mid    int64
+	Gid    int64
+	// Data 事件数据, 与同名 Action 推送的消息数据一致, 群消息为群消息指针
+	Data     json.RawMessage
+	CreateAt int64
+}
+
+type InboxSyncResponse struct {
+	Events []*InboxEventResponse
+	// Seq 本次同步到的最大 seq, 下次同步时使用
+	Seq int64
+	// More 是否还有更多事件未同步
+	More bool
+}
+
+type DeleteMessageRequest struct {
+	Mid   []int64
+	Group bool
+}
+
+type ClearHistoryRequest struct {
+	// To 单聊为对方 ID, 群聊为群 ID
+	To    int64
+	Group bool
+	// BeforeMid 清空该消息及之前的消息, 0 表示清空当前所有消息
+	BeforeMid int64
+}
+
+type SearchMessageRequest struct {
+	Keyword string
+	// To 指定会话, 单聊为对方 ID, 群聊为群 ID, 0 表示搜索所有单聊和群
+	To    int64
+	Group bool
+	// From 发送者
+	From int64
+	// StartAt, EndAt 消息时间范围, 秒
+	StartAt int64
+	EndAt   int64
+	Type    int32
+	// BeforeMid 分页, 返回该消息之前的结果
+	BeforeMid int64
+	Limit     int
+}
+
+type SearchMessageResponse struct {
+	Chat  []*MessageResponse
+	Group []*GroupMessageResponse
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/glide-im/glideim/im/api/comm"
 	route "github.com/glide-im/glideim/im/api/router"
 	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/groupdao"
 	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/dao/userdao"
 	"github.com/glide-im/glideim/im/message"
@@ -14,7 +15,7 @@ type GroupMsgApi struct {
 }
 
 func (*GroupMsgApi) GetRecentGroupMessage(ctx *route.Context, request *RecentGroupMessageRequest) error {
-	ms, err := msgdao.GroupMsgDaoImpl.GetLatestGroupMessage(request.Gid, 20)
+	ms, err := msgdao.GroupMsgDaoImpl.GetLatestGroupMessage(ctx.Uid, request.Gid, 20)
 	if err != nil && err != common.ErrNoRecordFound {
 		return comm.NewDbErr(err)
 	}
@@ -23,6 +24,9 @@ func (*GroupMsgApi) GetRecentGroupMessage(ctx *route.Context, request *RecentGro
 	for _, m := range ms {
 		resp = append(resp, dbGroupMsg2ResponseMsg(m))
 	}
+	if err = fillGroupMessageReactions(resp); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
 	return nil
 }
@@ -32,7 +36,7 @@ func (*GroupMsgApi) GetGroupMessageHistory(ctx *route.Context, request *GroupMsg
 	if request.BeforeSeq <= 0 {
 		before = math.MaxInt64
 	}
-	ms, err := msgdao.GroupMsgDaoImpl.GetGroupMessage(request.Gid, before, 20)
+	ms, err := msgdao.GroupMsgDaoImpl.GetGroupMessage(ctx.Uid, request.Gid, before, 20)
 	if err != nil {
 		return comm.NewDbErr(err)
 	}
@@ -41,20 +45,26 @@ func (*GroupMsgApi) GetGroupMessageHistory(ctx *route.Context, request *GroupMsg
 	for _, m := range ms {
 		resp = append(resp, dbGroupMsg2ResponseMsg(m))
 	}
+	if err = fillGroupMessageReactions(resp); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
 	return nil
 }
 
 func (*GroupMsgApi) GetGroupMessage(ctx *route.Context, request *GroupMessageRequest) error {
 
-	messages, err := msgdao.GroupMsgDaoImpl.GetMessages(request.Mid...)
+	messages, err := msgdao.GroupMsgDaoImpl.GetVisibleMessages(ctx.Uid, request.Mid...)
 	if err != nil {
 		return comm.NewDbErr(err)
 	}
-	resp := make([]*GroupMessageResponse, len(messages))
+	resp := make([]*GroupMessageResponse, 0, len(messages))
 	for _, m := range messages {
 		resp = append(resp, dbGroupMsg2ResponseMsg(m))
 	}
+	if err = fillGroupMessageReactions(resp); err != nil {
+		return comm.NewDbErr(err)
+	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
 	return nil
 }
@@ -91,8 +101,63 @@ func (*GroupMsgApi) GetGroupMessageState(ctx *route.Context, request *GroupMsgSt
 	return nil
 }
 
+// GetGroupMessageRead 获取群消息的已读和未读成员列表, 已读由成员确认收到的 seq 计算
+func (*GroupMsgApi) GetGroupMessageRead(ctx *route.Context, request *GroupMsgReadRequest) error {
+	isMember, err := groupdao.Dao.HasMember(request.Gid, ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if !isMember {
+		return errNotGroupMember
+	}
+	ms, err := msgdao.GroupMsgDaoImpl.GetGroupMessageSeqSpan(request.Gid, request.Seq, request.Seq)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	if len(ms) == 0 {
+		return errMessageNotExist
+	}
+	sender := ms[0].From
+
+	readUid, err := msgdao.GroupMsgDaoImpl.GetGroupMessageReadMembers(request.Gid, request.Seq)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	members, err := groupdao.Dao.GetMembers(request.Gid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	read := map[int64]struct{}{}
+	for _, uid := range readUid {
+		read[uid] = struct{}{}
+	}
+
+	//goland:noinspection GoPreferNilSlice
+	resp := GroupMsgReadResponse{
+		Gid:    request.Gid,
+		Seq:    request.Seq,
+		Mid:    ms[0].MID,
+		Read:   []int64{},
+		Unread: []int64{},
+	}
+	for _, mb := range members {
+		if mb.Uid == sender {
+			continue
+		}
+		if _, ok := read[mb.Uid]; ok {
+			resp.Read = append(resp.Read, mb.Uid)
+		} else {
+			resp.Unread = append(resp.Unread, mb.Uid)
+		}
+	}
+	resp.ReadCount = int64(len(resp.Read))
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// dbGroupMsg2ResponseMsg 转换群消息, 已撤回的消息不返回原内容
 func dbGroupMsg2ResponseMsg(m *msgdao.GroupMessage) *GroupMessageResponse {
-	return &GroupMessageResponse{
+	r := &GroupMessageResponse{
 		Mid:      m.MID,
 		Sender:   m.From,
 		Seq:      m.Seq,
@@ -102,5 +167,12 @@ func dbGroupMsg2ResponseMsg(m *msgdao.GroupMessage) *GroupMessageResponse {
 		Content:  m.Content,
 		Status:   m.Status,
 		RecallBy: m.RecallBy,
+		Edited:   m.EditAt > 0,
+		EditAt:   m.EditAt,
+		ExpireAt: m.ExpireAt,
+	}
+	if m.Status == msgdao.ChatMessageStatusRecalled {
+		r.Content = ""
 	}
+	return r
 }
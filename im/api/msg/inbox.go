@@ -0,0 +1,46 @@
+package msg
+
+import (
+	"encoding/json"
+	"github.com/glide-im/glideim/im/api/comm"
+	route "github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+)
+
+const inboxSyncMaxLimit = 200
+
+// SyncInbox 分页同步当前用户收件箱中指定 seq 之后的事件, 设备从上次同步到的 seq 继续即可追上所有事件
+func (*MsgApi) SyncInbox(ctx *route.Context, request *InboxSyncRequest) error {
+	limit := request.Limit
+	if limit <= 0 || limit > inboxSyncMaxLimit {
+		limit = inboxSyncMaxLimit
+	}
+	es, err := msgdao.InboxDaoImpl.GetInboxEvents(ctx.Uid, request.Seq, limit+1)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+
+	resp := &InboxSyncResponse{
+		//goland:noinspection GoPreferNilSlice
+		Events: []*InboxEventResponse{},
+		Seq:    request.Seq,
+		More:   len(es) > limit,
+	}
+	if resp.More {
+		es = es[:limit]
+	}
+	for _, e := range es {
+		resp.Events = append(resp.Events, &InboxEventResponse{
+			Seq:      e.Seq,
+			Action:   e.Action,
+			Mid:      e.MID,
+			Gid:      e.Gid,
+			Data:     json.RawMessage(e.Data),
+			CreateAt: e.CreateAt,
+		})
+		resp.Seq = e.Seq
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
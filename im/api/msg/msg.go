@@ -12,6 +12,9 @@ type MsgApi struct {
 	*ChatMsgApi
 }
 
+// GetMessageID 获取一个消息 ID.
+//
+// Deprecated: 消息 ID 已由服务端在收到消息时分配, 并在确认消息中返回, 仅为兼容旧客户端保留.
 func (MsgApi) GetMessageID(ctx *route.Context) error {
 	id, err := msgdao.GetMessageID()
 	if err != nil {
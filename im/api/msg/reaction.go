@@ -0,0 +1,66 @@
+package msg
+
+import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
+)
+
+// loadReactions 批量查询消息的表情回应, 并按消息 id, 表情聚合
+func loadReactions(mid []int64) (map[int64][]*ReactionResponse, error) {
+	rs, err := msgdao.ReactionDaoImpl.GetReactions(mid...)
+	if err != nil {
+		return nil, err
+	}
+	result := map[int64][]*ReactionResponse{}
+	index := map[int64]map[string]*ReactionResponse{}
+	for _, r := range rs {
+		emojis, ok := index[r.MID]
+		if !ok {
+			emojis = map[string]*ReactionResponse{}
+			index[r.MID] = emojis
+		}
+		rr, ok := emojis[r.Emoji]
+		if !ok {
+			//goland:noinspection GoPreferNilSlice
+			rr = &ReactionResponse{Emoji: r.Emoji, Uid: []int64{}}
+			emojis[r.Emoji] = rr
+			result[r.MID] = append(result[r.MID], rr)
+		}
+		rr.Count++
+		rr.Uid = append(rr.Uid, r.UID)
+	}
+	return result, nil
+}
+
+func fillChatMessageReactions(ms []*MessageResponse) error {
+	var mid []int64
+	for _, m := range ms {
+		mid = append(mid, m.Mid)
+	}
+	reactions, err := loadReactions(mid)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if r, ok := reactions[m.Mid]; ok {
+			m.Reactions = r
+		}
+	}
+	return nil
+}
+
+func fillGroupMessageReactions(ms []*GroupMessageResponse) error {
+	var mid []int64
+	for _, m := range ms {
+		mid = append(mid, m.Mid)
+	}
+	reactions, err := loadReactions(mid)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if r, ok := reactions[m.Mid]; ok {
+			m.Reactions = r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package msg
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/api/comm"
+	route "github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"time"
+)
+
+// CreateScheduledMessage 创建定时消息, 到达发送时间后由服务端以当前用户的身份发出
+func (*MsgApi) CreateScheduledMessage(ctx *route.Context, request *ScheduledMessageRequest) error {
+	now := time.Now().Unix()
+	if request.SendAt <= now || request.SendAt > now+config.Messaging.MaxScheduleAhead {
+		return errInvalidScheduleTime
+	}
+	if request.Group {
+		isMember, err := groupdao.Dao.HasMember(request.To, ctx.Uid)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		if !isMember {
+			return errNotGroupMember
+		}
+	}
+	m := &msgdao.ScheduledMessage{
+		From:     ctx.Uid,
+		To:       request.To,
+		Group:    request.Group,
+		Type:     request.Type,
+		Content:  request.Content,
+		SendAt:   request.SendAt,
+		Status:   msgdao.ScheduledMessageStatusPending,
+		CreateAt: now,
+	}
+	err := msgdao.ScheduledDaoImpl.AddScheduledMessage(m)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, scheduledModel2Response(m)))
+	return nil
+}
+
+// GetScheduledMessages 获取当前用户所有待发送的定时消息
+func (*MsgApi) GetScheduledMessages(ctx *route.Context) error {
+	ms, err := msgdao.ScheduledDaoImpl.GetScheduledMessages(ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*ScheduledMessageResponse{}
+	for _, m := range ms {
+		resp = append(resp, scheduledModel2Response(m))
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// CancelScheduledMessage 取消待发送的定时消息
+func (*MsgApi) CancelScheduledMessage(ctx *route.Context, request *CancelScheduledMessageRequest) error {
+	err := msgdao.ScheduledDaoImpl.CancelScheduledMessage(request.ID, ctx.Uid)
+	if err == common.ErrNoneUpdated {
+		return errScheduledNotExist
+	}
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func scheduledModel2Response(m *msgdao.ScheduledMessage) *ScheduledMessageResponse {
+	return &ScheduledMessageResponse{
+		ID:       m.ID,
+		To:       m.To,
+		Group:    m.Group,
+		Type:     m.Type,
+		Content:  m.Content,
+		SendAt:   m.SendAt,
+		CreateAt: m.CreateAt,
+	}
+}
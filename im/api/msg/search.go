@@ -0,0 +1,91 @@
+package msg
+
+import (
+	"github.com/glide-im/glideim/im/api/comm"
+	route "github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/search"
+)
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 50
+)
+
+// SearchMessage 按关键词搜索用户的单聊和群聊记录, 只返回用户所在群的消息, 已撤回, 删除和清空的消息不返回
+func (*MsgApi) SearchMessage(ctx *route.Context, request *SearchMessageRequest) error {
+	tokens := search.QueryTokens(request.Keyword)
+	if len(tokens) == 0 {
+		return errInvalidKeyword
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+	if limit > searchMaxLimit {
+		limit = searchMaxLimit
+	}
+	filter := &msgdao.SearchFilter{
+		Tokens:    tokens,
+		To:        request.To,
+		From:      request.From,
+		Type:      request.Type,
+		StartAt:   request.StartAt,
+		EndAt:     request.EndAt,
+		BeforeMid: request.BeforeMid,
+		Limit:     limit,
+	}
+
+	resp := SearchMessageResponse{
+		Chat:  []*MessageResponse{},
+		Group: []*GroupMessageResponse{},
+	}
+	if request.To == 0 || !request.Group {
+		ms, err := msgdao.SearchDaoImpl.SearchChatMessages(ctx.Uid, filter)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		for _, m := range ms {
+			resp.Chat = append(resp.Chat, messageModel2MessageResponse(m))
+		}
+		if err = fillChatMessageReactions(resp.Chat); err != nil {
+			return comm.NewDbErr(err)
+		}
+	}
+	if request.To == 0 || request.Group {
+		var gid []int64
+		if request.To != 0 {
+			isMember, err := groupdao.Dao.HasMember(request.To, ctx.Uid)
+			if err != nil {
+				return comm.NewDbErr(err)
+			}
+			if !isMember {
+				return errNotGroupMember
+			}
+			gid = []int64{request.To}
+		} else {
+			var err error
+			gid, err = groupdao.Dao.GetMemberGroups(ctx.Uid)
+			if err != nil {
+				return comm.NewDbErr(err)
+			}
+		}
+		// 群 ID 已经限定了会话, 不再按 To 过滤
+		groupFilter := *filter
+		groupFilter.To = 0
+		ms, err := msgdao.SearchDaoImpl.SearchGroupMessages(ctx.Uid, gid, &groupFilter)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		for _, m := range ms {
+			resp.Group = append(resp.Group, dbGroupMsg2ResponseMsg(m))
+		}
+		if err = fillGroupMessageReactions(resp.Group); err != nil {
+			return comm.NewDbErr(err)
+		}
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
@@ -1,21 +1,67 @@
 package msg
 
 import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/api/apidep"
 	"github.com/glide-im/glideim/im/api/comm"
 	"github.com/glide-im/glideim/im/api/router"
 	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
 	"time"
 )
 
 func (*MsgApi) ReadMessage(ctx *route.Context, request *ReadMessageRequest) error {
-	//err := msgdao.SessionDaoImpl.CleanUserSessionUnread(ctx.Uid, request.To, request.To)
-	//if err != nil {
-	//	return comm.NewDbErr(err)
-	//}
+	mid, err := msgdao.SessionDaoImpl.UpdateUserSessionRead(ctx.Uid, request.To, ctx.Uid, request.Mid)
+	if err == msgdao.ErrReadMidInvalid || err == msgdao.ErrSessionNotExist {
+		return errMessageNotExist
+	}
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	r := message.Read{}
+	r.Mid = mid
+	r.From = ctx.Uid
+	r.To = request.To
+	r.ReadAt = time.Now().Unix()
+	apidep.SendMessage(request.To, 0, message.NewMessage(-1, message.ActionChatMessageRead, &r))
+
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ReadMessageResponse{To: request.To, Mid: mid}))
 	return nil
 }
 
+// SetSessionTTL 设置会话的消息存活时间, 只对之后发送的消息生效, 修改后通知会话双方的所有设备
+func (*MsgApi) SetSessionTTL(ctx *route.Context, request *SessionTTLRequest) error {
+	if request.TTL < 0 || request.TTL > config.Messaging.MaxMessageTTL {
+		return errInvalidTTL
+	}
+	err := msgdao.SessionDaoImpl.SetSessionTTL(ctx.Uid, request.To, request.TTL)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	t := &message.SessionTTL{}
+	t.From = ctx.Uid
+	t.To = request.To
+	t.TTL = request.TTL
+	err = msgdao.AddInboxEvent(message.ActionSessionTTL, 0, 0, t, ctx.Uid, request.To)
+	if err != nil {
+		logger.E("write inbox event error %v", err)
+	}
+	n := message.NewMessage(-1, message.ActionSessionTTL, t)
+	apidep.SendMessage(ctx.Uid, 0, n)
+	apidep.SendMessage(request.To, 0, n)
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+// sessionUnreadAndRead 返回 uid 在会话中的未读数, 已读位置和对方的已读位置
+func sessionUnreadAndRead(s *msgdao.Session, uid int64) (int64, int64, int64) {
+	if s.Uid == uid {
+		return s.LgUidUnread, s.LgUidRead, s.SmUidRead
+	}
+	return s.SmUidUnread, s.SmUidRead, s.LgUidRead
+}
+
 func (*MsgApi) GetOrCreateSession(ctx *route.Context, request *SessionRequest) error {
 	session, err := msgdao.SessionDaoImpl.GetSession(ctx.Uid, request.To)
 	if err != nil {
@@ -29,12 +75,18 @@ func (*MsgApi) GetOrCreateSession(ctx *route.Context, request *SessionRequest) e
 		session = se
 	}
 
+	unread, read, peerRead := sessionUnreadAndRead(session, ctx.Uid)
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, SessionResponse{
-		Uid1:     session.Uid,
-		Uid2:     session.Uid2,
-		LastMid:  session.LastMID,
-		UpdateAt: session.UpdateAt,
-		CreateAt: session.CreateAt,
+		Uid1:        session.Uid,
+		Uid2:        session.Uid2,
+		To:          request.To,
+		Unread:      unread,
+		ReadMid:     read,
+		PeerReadMid: peerRead,
+		LastMid:     session.LastMID,
+		TTL:         session.TTL,
+		UpdateAt:    session.UpdateAt,
+		CreateAt:    session.CreateAt,
 	}))
 
 	return nil
@@ -54,13 +106,18 @@ func (a *MsgApi) GetRecentSessions(ctx *route.Context) error {
 			to = s.Uid
 		}
 
+		unread, read, peerRead := sessionUnreadAndRead(s, ctx.Uid)
 		sr = append(sr, &SessionResponse{
-			Uid2:     s.Uid,
-			Uid1:     s.Uid2,
-			To:       to,
-			LastMid:  s.LastMID,
-			UpdateAt: s.UpdateAt,
-			CreateAt: s.CreateAt,
+			Uid2:        s.Uid,
+			Uid1:        s.Uid2,
+			To:          to,
+			Unread:      unread,
+			ReadMid:     read,
+			PeerReadMid: peerRead,
+			LastMid:     s.LastMID,
+			TTL:         s.TTL,
+			UpdateAt:    s.UpdateAt,
+			CreateAt:    s.CreateAt,
 		})
 	}
 	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, sr))
@@ -0,0 +1,138 @@
+package msg
+
+import (
+	"github.com/glide-im/glideim/im/api/apidep"
+	"github.com/glide-im/glideim/im/api/comm"
+	route "github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"time"
+)
+
+// DeleteMessage 仅为自己删除消息, 只能删除自己参与的单聊消息或所在群的消息, 删除后同步给自己的所有设备
+func (*MsgApi) DeleteMessage(ctx *route.Context, request *DeleteMessageRequest) error {
+	//goland:noinspection GoPreferNilSlice
+	mid := []int64{}
+	if request.Group {
+		ms, err := msgdao.GroupMsgDaoImpl.GetMessages(request.Mid...)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		member := map[int64]bool{}
+		for _, m := range ms {
+			isMember, ok := member[m.To]
+			if !ok {
+				isMember, err = groupdao.Dao.HasMember(m.To, ctx.Uid)
+				if err != nil {
+					return comm.NewDbErr(err)
+				}
+				member[m.To] = isMember
+			}
+			if isMember {
+				mid = append(mid, m.MID)
+			}
+		}
+	} else {
+		ms, err := msgdao.GetChatMessage(request.Mid...)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		for _, m := range ms {
+			if m.From == ctx.Uid || m.To == ctx.Uid {
+				mid = append(mid, m.MID)
+			}
+		}
+	}
+	if len(mid) == 0 {
+		return errMessageNotExist
+	}
+
+	err := msgdao.VisibilityDaoImpl.HideMessages(ctx.Uid, mid...)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	d := &message.Delete{}
+	d.Mid = mid
+	d.Group = request.Group
+	syncToUserDevices(ctx.Uid, message.ActionMessageDelete, 0, d)
+
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, d))
+	return nil
+}
+
+// ClearHistory 清空自己在单聊或群中指定消息及之前的聊天记录, 清空后同步给自己的所有设备
+func (*MsgApi) ClearHistory(ctx *route.Context, request *ClearHistoryRequest) error {
+	c := &message.ClearHistory{}
+	c.To = request.To
+	c.Group = request.Group
+	c.BeforeMid = request.BeforeMid
+
+	position := request.BeforeMid
+	if request.Group {
+		isMember, err := groupdao.Dao.HasMember(request.To, ctx.Uid)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		if !isMember {
+			return errNotGroupMember
+		}
+		if request.BeforeMid > 0 {
+			m, err := msgdao.GroupMsgDaoImpl.GetMessage(request.BeforeMid)
+			if err != nil || m.To != request.To {
+				return errMessageNotExist
+			}
+			c.BeforeSeq = m.Seq
+		} else {
+			state, err := msgdao.GroupMsgDaoImpl.GetGroupMessageState(request.To)
+			if err != nil {
+				return comm.NewDbErr(err)
+			}
+			c.BeforeMid = state.LastMID
+			c.BeforeSeq = state.LastSeq
+		}
+		position = c.BeforeSeq
+	} else if request.BeforeMid > 0 {
+		// 单聊的消息 ID 不保证按时间递增, 清空位置使用消息的创建时间
+		ms, err := msgdao.GetChatMessage(request.BeforeMid)
+		if err != nil || !isChatMessageOf(ms[0], ctx.Uid, request.To) {
+			return errMessageNotExist
+		}
+		position = ms[0].CreateAt
+	} else {
+		session, err := msgdao.SessionDaoImpl.GetSession(ctx.Uid, request.To)
+		if err != nil {
+			return comm.NewDbErr(err)
+		}
+		c.BeforeMid = session.LastMID
+		position = time.Now().Unix()
+	}
+
+	err := msgdao.VisibilityDaoImpl.ClearHistory(ctx.Uid, request.To, request.Group, position)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	gid := int64(0)
+	if request.Group {
+		gid = request.To
+	}
+	syncToUserDevices(ctx.Uid, message.ActionMessageClear, gid, c)
+
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, c))
+	return nil
+}
+
+// syncToUserDevices 将删除, 清空事件推送给用户所有在线设备, 并写入收件箱供离线设备同步
+func syncToUserDevices(uid int64, action message.Action, gid int64, data interface{}) {
+	err := msgdao.AddInboxEvent(string(action), 0, gid, data, uid)
+	if err != nil {
+		logger.E("write inbox event error %v", err)
+	}
+	apidep.SendMessage(uid, 0, message.NewMessage(-1, action, data))
+}
+
+// isChatMessageOf 消息是否属于 uid 与 peer 的单聊
+func isChatMessageOf(m *msgdao.ChatMessage, uid int64, peer int64) bool {
+	return (m.From == uid && m.To == peer) || (m.From == peer && m.To == uid)
+}
@@ -0,0 +1,9 @@
+package pushes
+
+import "github.com/glide-im/glideim/im/api/comm"
+
+var (
+	errInvalidPlatform = comm.NewApiBizError(7001, "invalid push platform")
+	errInvalidToken    = comm.NewApiBizError(7002, "invalid push token")
+	errInvalidDnd      = comm.NewApiBizError(7003, "invalid do-not-disturb time")
+)
@@ -0,0 +1,36 @@
+package pushes
+
+type RegisterTokenRequest struct {
+	// Platform 推送平台, apns, fcm 或 webpush
+	Platform string
+	// Token 设备的推送 token, Web Push 为 PushSubscription 的 json
+	Token string
+}
+
+type PushSettingRequest struct {
+	// DndStart, DndEnd 免打扰时段, 一天中的分钟数, 相等时不开启, DndStart 大于 DndEnd 时跨越零点
+	DndStart int
+	DndEnd   int
+	// TzOffset 用户时区相对 UTC 的偏移, 单位分钟
+	TzOffset    int
+	HidePreview bool
+}
+
+type PushSettingResponse struct {
+	DndStart    int
+	DndEnd      int
+	TzOffset    int
+	HidePreview bool
+}
+
+type MuteRequest struct {
+	// Target 单聊为对方 uid, 群聊为群 ID
+	Target int64
+	Group  bool
+	Mute   bool
+}
+
+type MuteResponse struct {
+	Target int64
+	Group  bool
+}
@@ -0,0 +1,115 @@
+package pushes
+
+import (
+	"github.com/glide-im/glideim/im/api/comm"
+	"github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/push"
+)
+
+const (
+	tokenMaxLen  = 4096
+	minutesOfDay = 24 * 60
+	// tzOffsetMax 时区偏移范围 UTC-12:00 到 UTC+14:00
+	tzOffsetMin = -12 * 60
+	tzOffsetMax = 14 * 60
+)
+
+// PushApi 注册当前设备的推送 token 和修改推送设置
+type PushApi struct {
+}
+
+// RegisterToken 注册当前设备的推送 token, 替换该设备原有的 token
+func (*PushApi) RegisterToken(ctx *route.Context, request *RegisterTokenRequest) error {
+	switch request.Platform {
+	case pushdao.PlatformAPNs, pushdao.PlatformFCM, pushdao.PlatformWebPush:
+	default:
+		return errInvalidPlatform
+	}
+	if request.Token == "" || len(request.Token) > tokenMaxLen {
+		return errInvalidToken
+	}
+	if request.Platform == pushdao.PlatformWebPush && !push.ValidWebPushToken(request.Token) {
+		return errInvalidToken
+	}
+	err := pushdao.Dao.SaveToken(&pushdao.PushToken{
+		Uid:      ctx.Uid,
+		Device:   ctx.Device,
+		Platform: request.Platform,
+		Token:    request.Token,
+	})
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+// RemoveToken 删除当前设备的推送 token, 该设备不再接收推送
+func (*PushApi) RemoveToken(ctx *route.Context) error {
+	err := pushdao.Dao.RemoveToken(ctx.Uid, ctx.Device)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func (*PushApi) GetSetting(ctx *route.Context) error {
+	s, err := pushdao.Dao.GetSetting(ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, &PushSettingResponse{
+		DndStart:    s.DndStart,
+		DndEnd:      s.DndEnd,
+		TzOffset:    s.TzOffset,
+		HidePreview: s.HidePreview,
+	}))
+	return nil
+}
+
+func (*PushApi) UpdateSetting(ctx *route.Context, request *PushSettingRequest) error {
+	if request.DndStart < 0 || request.DndStart >= minutesOfDay ||
+		request.DndEnd < 0 || request.DndEnd >= minutesOfDay ||
+		request.TzOffset < tzOffsetMin || request.TzOffset > tzOffsetMax {
+		return errInvalidDnd
+	}
+	err := pushdao.Dao.SaveSetting(&pushdao.PushSetting{
+		Uid:         ctx.Uid,
+		DndStart:    request.DndStart,
+		DndEnd:      request.DndEnd,
+		TzOffset:    request.TzOffset,
+		HidePreview: request.HidePreview,
+	})
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+// Mute 开启或关闭会话的消息免打扰, 免打扰的会话不推送通知
+func (*PushApi) Mute(ctx *route.Context, request *MuteRequest) error {
+	err := pushdao.Dao.SetMute(ctx.Uid, request.Target, request.Group, request.Mute)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func (*PushApi) GetMutes(ctx *route.Context) error {
+	ms, err := pushdao.Dao.GetMutes(ctx.Uid)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*MuteResponse{}
+	for _, m := range ms {
+		resp = append(resp, &MuteResponse{Target: m.Target, Group: m.Group})
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
@@ -3,11 +3,15 @@ package api
 import (
 	"github.com/glide-im/glideim/im/api/app"
 	"github.com/glide-im/glideim/im/api/auth"
+	"github.com/glide-im/glideim/im/api/bots"
 	"github.com/glide-im/glideim/im/api/cs"
 	"github.com/glide-im/glideim/im/api/groups"
 	"github.com/glide-im/glideim/im/api/msg"
+	"github.com/glide-im/glideim/im/api/pushes"
+	"github.com/glide-im/glideim/im/api/server"
 	"github.com/glide-im/glideim/im/api/test"
 	"github.com/glide-im/glideim/im/api/user"
+	"github.com/glide-im/glideim/im/api/webhooks"
 )
 
 func initRoute() {
@@ -23,6 +27,16 @@ func initRoute() {
 	postNoAuth("/api/auth/guest", authApi.GuestRegister)
 	postNoAuth("/api/auth/signin", authApi.SignIn)
 	postNoAuth("/api/auth/token", authApi.AuthToken)
+
+	// 机器人接口和服务端接口不使用用户认证, 需要在用户认证中间件注册前注册
+	botApi := bots.BotApi{}
+	postBotAuth("/api/bot/me", botApi.GetMe)
+	postBotAuth("/api/bot/updates", botApi.GetUpdates)
+	postBotAuth("/api/bot/send", botApi.SendMessage)
+
+	serverApi := server.ServerApi{}
+	postServerAuth("/api/server/send", serverApi.SendMessage)
+
 	post("/api/auth/logout", authApi.Logout)
 
 	groupApi := groups.GroupApi{}
@@ -32,6 +46,10 @@ func initRoute() {
 	post("/api/group/join", groupApi.JoinGroup)
 	post("/api/group/members/invite", groupApi.AddGroupMember)
 	post("/api/group/members/remove", groupApi.RemoveMember)
+	post("/api/group/ttl", groupApi.SetGroupMsgTTL)
+	post("/api/group/bot/add", groupApi.AddBot)
+	post("/api/group/bot/update", groupApi.UpdateBot)
+	post("/api/group/bot/remove", groupApi.RemoveBot)
 
 	userApi := user.UserApi{}
 	post("/api/contacts/add", userApi.AddContact)
@@ -51,6 +69,7 @@ func initRoute() {
 	post("/api/msg/group/recent", msgApi.GetRecentGroupMessage)
 	post("/api/msg/group/state", msgApi.GetGroupMessageState)
 	post("/api/msg/group/state/all", msgApi.GetUserGroupMessageState)
+	post("/api/msg/group/read", msgApi.GetGroupMessageRead)
 
 	post("/api/msg/chat/history", msgApi.GetChatMessageHistory)
 	post("/api/msg/chat/user", msgApi.GetRecentMessageByUser)
@@ -58,11 +77,45 @@ func initRoute() {
 	post("/api/msg/chat/offline", msgApi.GetOfflineMessage)
 	post("/api/msg/chat/offline/ack", msgApi.AckOfflineMessage)
 
+	post("/api/msg/scheduled/create", msgApi.CreateScheduledMessage)
+	post("/api/msg/scheduled/list", msgApi.GetScheduledMessages)
+	post("/api/msg/scheduled/cancel", msgApi.CancelScheduledMessage)
+
+	post("/api/msg/inbox/sync", msgApi.SyncInbox)
+	post("/api/msg/delete", msgApi.DeleteMessage)
+	post("/api/msg/clear", msgApi.ClearHistory)
+	post("/api/msg/search", msgApi.SearchMessage)
+
 	post("/api/session/recent", msgApi.GetRecentSessions)
 	post("/api/session/get", msgApi.GetOrCreateSession)
+	post("/api/session/read", msgApi.ReadMessage)
+	post("/api/session/ttl", msgApi.SetSessionTTL)
 
 	csApi := cs.CsApi{}
 	post("/api/cs/get", csApi.GetRecentChatMessage)
+
+	webhookApi := webhooks.WebhookApi{}
+	post("/api/webhook/create", webhookApi.CreateWebhook)
+	post("/api/webhook/update", webhookApi.UpdateWebhook)
+	post("/api/webhook/delete", webhookApi.DeleteWebhook)
+	post("/api/webhook/list", webhookApi.GetWebhooks)
+	post("/api/webhook/deliveries", webhookApi.GetDeliveries)
+	post("/api/webhook/attempts", webhookApi.GetAttempts)
+	post("/api/webhook/redeliver", webhookApi.Redeliver)
+
+	post("/api/bot/create", botApi.CreateBot)
+	post("/api/bot/update", botApi.UpdateBot)
+	post("/api/bot/list", botApi.GetBots)
+	post("/api/bot/token/reset", botApi.ResetToken)
+	post("/api/bot/delete", botApi.DeleteBot)
+
+	pushApi := pushes.PushApi{}
+	post("/api/push/token/register", pushApi.RegisterToken)
+	post("/api/push/token/remove", pushApi.RemoveToken)
+	post("/api/push/setting/get", pushApi.GetSetting)
+	post("/api/push/setting/update", pushApi.UpdateSetting)
+	post("/api/push/mute", pushApi.Mute)
+	post("/api/push/mute/list", pushApi.GetMutes)
 }
 
 func postNoAuth(path string, fn interface{}) {
@@ -71,6 +124,12 @@ func postNoAuth(path string, fn interface{}) {
 func getNoAuth(path string, fn interface{}) {
 	rt.GET(path, getHandler(path, fn))
 }
+func postBotAuth(path string, fn interface{}) {
+	rt.POST(path, botAuthMiddleware, getHandler(path, fn))
+}
+func postServerAuth(path string, fn interface{}) {
+	rt.POST(path, serverAuthMiddleware, getHandler(path, fn))
+}
 func post(path string, fn interface{}) {
 	useAuth().POST(path, getHandler(path, fn))
 }
@@ -0,0 +1,11 @@
+package server
+
+import "github.com/glide-im/glideim/im/api/comm"
+
+var (
+	errServerApiDisabled = comm.NewApiBizError(6001, "server api is disabled")
+	errInvalidSender     = comm.NewApiBizError(6002, "sender must be a system or bot uid")
+	errInvalidKind       = comm.NewApiBizError(6003, "invalid message kind")
+	errInvalidTargets    = comm.NewApiBizError(6004, "no targets or too many targets")
+	errEmptyContent      = comm.NewApiBizError(6005, "empty message content")
+)
@@ -0,0 +1,18 @@
+package server
+
+import "github.com/glide-im/glideim/im/messaging"
+
+type SendMessageRequest struct {
+	// From 发送者, 系统账号或机器人 uid
+	From int64
+	// Kind 消息类型, chat 单聊, group 群聊, custom 自定义消息
+	Kind string
+	// To 单聊和自定义消息为接收者 uid, 群聊为群 ID
+	To      []int64
+	Type    int32
+	Content string
+}
+
+type SendMessageResponse struct {
+	Results []*messaging.SendResult
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/glide-im/glideim/im/api/apidep"
+	"github.com/glide-im/glideim/im/api/comm"
+	"github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/messaging"
+)
+
+// ServerApi 业务后端以系统账号或机器人发送消息, 以配置中的密钥认证
+type ServerApi struct {
+}
+
+func (*ServerApi) SendMessage(ctx *route.Context, request *SendMessageRequest) error {
+	results, err := apidep.SendMessages(&messaging.SendRequest{
+		From:    request.From,
+		Kind:    request.Kind,
+		To:      request.To,
+		Type:    request.Type,
+		Content: request.Content,
+	})
+	switch err {
+	case nil:
+	case messaging.ErrServerApiDisabled:
+		return errServerApiDisabled
+	case messaging.ErrInvalidSender:
+		return errInvalidSender
+	case messaging.ErrInvalidKind:
+		return errInvalidKind
+	case messaging.ErrInvalidTargets:
+		return errInvalidTargets
+	case messaging.ErrEmptyContent:
+		return errEmptyContent
+	default:
+		return comm.NewUnexpectedErr("send message failed", err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, &SendMessageResponse{Results: results}))
+	return nil
+}
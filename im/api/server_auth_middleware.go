@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/glide-im/glideim/im/messaging"
+	"net/http"
+	"strings"
+)
+
+const serverAuthPrefix = "Key "
+
+// serverAuthMiddleware 服务端接口以 Authorization: Key <key> 认证, 密钥在配置 ServerApi.Keys 中
+func serverAuthMiddleware(context *gin.Context) {
+	authHeader := context.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, serverAuthPrefix) || !messaging.CheckApiKey(strings.TrimPrefix(authHeader, serverAuthPrefix)) {
+		context.Status(http.StatusUnauthorized)
+		context.Abort()
+		return
+	}
+	context.Next()
+}
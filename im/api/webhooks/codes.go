@@ -0,0 +1,11 @@
+package webhooks
+
+import "github.com/glide-im/glideim/im/api/comm"
+
+var (
+	errPermissionDenied = comm.NewApiBizError(4001, "permission denied")
+	errInvalidUrl       = comm.NewApiBizError(4002, "invalid webhook url")
+	errInvalidEvent     = comm.NewApiBizError(4003, "invalid webhook event")
+	errWebhookNotExist  = comm.NewApiBizError(4004, "webhook not exist")
+	errDeliveryNotExist = comm.NewApiBizError(4005, "delivery not exist")
+)
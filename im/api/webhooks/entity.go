@@ -0,0 +1,69 @@
+package webhooks
+
+type CreateWebhookRequest struct {
+	Url string
+	// Events 订阅的事件, * 表示所有事件
+	Events      []string
+	Description string
+	// Secret 签名密钥, 为空时由服务端生成
+	Secret string
+}
+
+type UpdateWebhookRequest struct {
+	Id          int64
+	Url         string
+	Events      []string
+	Description string
+	Enabled     bool
+}
+
+type WebhookRequest struct {
+	Id int64
+}
+
+type WebhookResponse struct {
+	Id     int64
+	Url    string
+	Events []string
+	// Secret 仅在创建时返回
+	Secret      string `json:",omitempty"`
+	Description string
+	Enabled     bool
+	CreateAt    int64
+	UpdateAt    int64
+}
+
+type DeliveriesRequest struct {
+	// WebhookId 为 0 时返回所有订阅的推送记录
+	WebhookId int64
+	// Status 0 待推送, 1 成功, 2 死信, 小于 0 表示所有状态
+	Status   int
+	BeforeId int64
+	Limit    int
+}
+
+type DeliveryResponse struct {
+	Id            int64
+	WebhookId     int64
+	EventId       string
+	Event         string
+	Payload       string
+	Status        int
+	Attempts      int
+	NextAttemptAt int64
+	LastError     string
+	CreateAt      int64
+	UpdateAt      int64
+}
+
+type DeliveryRequest struct {
+	Id int64
+}
+
+type AttemptResponse struct {
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   int64
+	CreateAt   int64
+}
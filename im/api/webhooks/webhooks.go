@@ -0,0 +1,258 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/api/comm"
+	"github.com/glide-im/glideim/im/api/router"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/webhookdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/webhook"
+	"github.com/glide-im/glideim/pkg/logger"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	deliveriesDefaultLimit = 20
+	deliveriesMaxLimit     = 100
+)
+
+// WebhookApi 管理事件订阅和查看推送记录, 仅配置中的管理员可以访问
+type WebhookApi struct {
+}
+
+func (*WebhookApi) CreateWebhook(ctx *route.Context, request *CreateWebhookRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	events, err := checkWebhook(request.Url, request.Events)
+	if err != nil {
+		return err
+	}
+	secret := request.Secret
+	if secret == "" {
+		secret = newSecret()
+	}
+	now := time.Now().Unix()
+	w := &webhookdao.Webhook{
+		Url:         request.Url,
+		Secret:      secret,
+		Events:      events,
+		Description: request.Description,
+		Enabled:     true,
+		CreateAt:    now,
+		UpdateAt:    now,
+	}
+	err = webhookdao.Dao.CreateWebhook(w)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	reload()
+	resp := webhook2Response(w)
+	resp.Secret = w.Secret
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+func (*WebhookApi) UpdateWebhook(ctx *route.Context, request *UpdateWebhookRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	w, err := getWebhook(request.Id)
+	if err != nil {
+		return err
+	}
+	w.Events, err = checkWebhook(request.Url, request.Events)
+	if err != nil {
+		return err
+	}
+	w.Url = request.Url
+	w.Description = request.Description
+	w.Enabled = request.Enabled
+	err = webhookdao.Dao.UpdateWebhook(w)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	reload()
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, webhook2Response(w)))
+	return nil
+}
+
+func (*WebhookApi) DeleteWebhook(ctx *route.Context, request *WebhookRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	err := webhookdao.Dao.DeleteWebhook(request.Id)
+	if err == common.ErrNoneUpdated {
+		return errWebhookNotExist
+	}
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	reload()
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, ""))
+	return nil
+}
+
+func (*WebhookApi) GetWebhooks(ctx *route.Context) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	ws, err := webhookdao.Dao.GetWebhooks(false)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*WebhookResponse{}
+	for _, w := range ws {
+		resp = append(resp, webhook2Response(w))
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// GetDeliveries 查看推送记录, Status 为 2 时即死信列表
+func (*WebhookApi) GetDeliveries(ctx *route.Context, request *DeliveriesRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = deliveriesDefaultLimit
+	}
+	if limit > deliveriesMaxLimit {
+		limit = deliveriesMaxLimit
+	}
+	ds, err := webhookdao.Dao.GetDeliveries(request.WebhookId, request.Status, request.BeforeId, limit)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*DeliveryResponse{}
+	for _, d := range ds {
+		resp = append(resp, delivery2Response(d))
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// GetAttempts 查看一次推送的所有尝试
+func (*WebhookApi) GetAttempts(ctx *route.Context, request *DeliveryRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	as, err := webhookdao.Dao.GetAttempts(request.Id)
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	//goland:noinspection GoPreferNilSlice
+	resp := []*AttemptResponse{}
+	for _, a := range as {
+		resp = append(resp, &AttemptResponse{
+			Attempt:    a.Attempt,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			Duration:   a.Duration,
+			CreateAt:   a.CreateAt,
+		})
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, resp))
+	return nil
+}
+
+// Redeliver 重新推送死信列表中或已完成的推送
+func (*WebhookApi) Redeliver(ctx *route.Context, request *DeliveryRequest) error {
+	if !isAdmin(ctx.Uid) {
+		return errPermissionDenied
+	}
+	d, err := webhook.Redeliver(request.Id)
+	if err == common.ErrNoRecordFound {
+		return errDeliveryNotExist
+	}
+	if err != nil {
+		return comm.NewDbErr(err)
+	}
+	ctx.Response(message.NewMessage(ctx.Seq, comm.ActionSuccess, delivery2Response(d)))
+	return nil
+}
+
+func isAdmin(uid int64) bool {
+	for _, id := range config.Webhook.Admins {
+		if id == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func getWebhook(id int64) (*webhookdao.Webhook, error) {
+	w, err := webhookdao.Dao.GetWebhook(id)
+	if err == common.ErrNoRecordFound {
+		return nil, errWebhookNotExist
+	}
+	if err != nil {
+		return nil, comm.NewDbErr(err)
+	}
+	return w, nil
+}
+
+// checkWebhook 检查推送地址和订阅的事件, 返回逗号分隔的事件
+func checkWebhook(rawUrl string, events []string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errInvalidUrl
+	}
+	if len(events) == 0 {
+		return "", errInvalidEvent
+	}
+	for _, e := range events {
+		if !webhook.ValidEvent(e) {
+			return "", errInvalidEvent
+		}
+	}
+	return strings.Join(events, ","), nil
+}
+
+func reload() {
+	if err := webhook.Reload(); err != nil {
+		logger.E("reload webhook subscriptions error %v", err)
+	}
+}
+
+func newSecret() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func webhook2Response(w *webhookdao.Webhook) *WebhookResponse {
+	return &WebhookResponse{
+		Id:          w.ID,
+		Url:         w.Url,
+		Events:      strings.Split(w.Events, ","),
+		Description: w.Description,
+		Enabled:     w.Enabled,
+		CreateAt:    w.CreateAt,
+		UpdateAt:    w.UpdateAt,
+	}
+}
+
+func delivery2Response(d *webhookdao.WebhookDelivery) *DeliveryResponse {
+	return &DeliveryResponse{
+		Id:            d.ID,
+		WebhookId:     d.WebhookID,
+		EventId:       d.EventID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+		CreateAt:      d.CreateAt,
+		UpdateAt:      d.UpdateAt,
+	}
+}
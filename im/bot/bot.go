@@ -0,0 +1,291 @@
+// Package bot 机器人账号, 机器人以 token 认证, 通过推送或长轮询接收发给它的单聊消息和群内 @ 它的消息
+package bot
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/glide-im/glideim/im/dao/botdao"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/dao/uid"
+	"github.com/glide-im/glideim/im/dao/userdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/webhook"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/panjf2000/ants/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Device 机器人通过 HTTP 接口发送消息时使用的设备
+const Device int64 = 0
+
+const (
+	// cacheTTL 机器人信息缓存时间, 其他节点修改的推送订阅在缓存过期后生效
+	cacheTTL = time.Minute
+	// pollInterval 长轮询时查询数据库的间隔, 用于发现其他节点写入的消息
+	pollInterval = time.Second
+)
+
+var ErrInvalidToken = errors.New("invalid bot token")
+
+// Update 机器人收到的消息
+type Update struct {
+	// Id 长轮询消息 ID, 推送时为 0
+	Id      int64
+	Action  string
+	Message json.RawMessage
+}
+
+type cachedBot struct {
+	bot      *botdao.Bot
+	expireAt time.Time
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = map[int64]*cachedBot{}
+
+	waitMu  sync.Mutex
+	waiters = map[int64]chan struct{}{}
+
+	execPool *ants.Pool
+)
+
+func init() {
+	var err error
+	execPool, err = ants.NewPool(1000,
+		ants.WithNonblocking(true),
+		ants.WithPreAlloc(false),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewToken 生成机器人 token 及其哈希, token 以机器人 uid 开头
+func NewToken(botUid int64) (token string, hash string) {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	token = strconv.FormatInt(botUid, 10) + ":" + hex.EncodeToString(b)
+	return token, HashToken(token)
+}
+
+// HashToken 数据库中只保存 token 的哈希
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// Authenticate 使用 token 认证机器人
+func Authenticate(token string) (*botdao.Bot, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	b, err := botdao.Dao.GetBotByToken(HashToken(token))
+	if err == common.ErrNoRecordFound {
+		return nil, ErrInvalidToken
+	}
+	return b, err
+}
+
+// GetBot 获取机器人信息, uid 不是机器人时返回 nil
+func GetBot(botUid int64) (*botdao.Bot, error) {
+	if !uid.IsSystemId(botUid) {
+		return nil, nil
+	}
+	cacheMu.RLock()
+	c, ok := cache[botUid]
+	cacheMu.RUnlock()
+	if ok && time.Now().Before(c.expireAt) {
+		return c.bot, nil
+	}
+	b, err := botdao.Dao.GetBot(botUid)
+	if err == common.ErrNoRecordFound {
+		b, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	cache[botUid] = &cachedBot{bot: b, expireAt: time.Now().Add(cacheTTL)}
+	cacheMu.Unlock()
+	return b, nil
+}
+
+// IsBot uid 是否为机器人
+func IsBot(botUid int64) bool {
+	b, err := GetBot(botUid)
+	if err != nil {
+		logger.E("get bot %d error %v", botUid, err)
+	}
+	return b != nil
+}
+
+// Invalidate 机器人信息修改或删除后清除缓存
+func Invalidate(botUid int64) {
+	cacheMu.Lock()
+	delete(cache, botUid)
+	cacheMu.Unlock()
+}
+
+// CanMessage 机器人是否可以给用户发送单聊消息, 用户需要添加机器人为联系人或者先给机器人发送过消息
+func CanMessage(botUid int64, to int64) (bool, error) {
+	ok, err := userdao.ContactsDao.HasContacts(to, botUid, userdao.ContactsTypeUser)
+	if err != nil || ok {
+		return ok, err
+	}
+	return msgdao.ChatMsgDaoImpl.HasChatMessageFrom(to, botUid)
+}
+
+// Mentioned 消息内容中是否 @ 了机器人, 以 @uid 或 @name 表示
+func Mentioned(content string, botUid int64, name string) bool {
+	if mentioned(content, "@"+strconv.FormatInt(botUid, 10)) {
+		return true
+	}
+	return name != "" && mentioned(content, "@"+name)
+}
+
+// mentioned content 中是否有 at, 且 at 之后不紧跟字母, 数字或下划线, 避免 @1001 匹配 @10012
+func mentioned(content string, at string) bool {
+	for i := strings.Index(content, at); i >= 0; {
+		end := i + len(at)
+		if end == len(content) {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(content[end:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return true
+		}
+		next := strings.Index(content[end:], at)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+	return false
+}
+
+// Deliver 将单聊消息投递给机器人, to 不是机器人时返回 false
+func Deliver(to int64, action message.Action, msg *message.ChatMessage) bool {
+	b, err := GetBot(to)
+	if err != nil {
+		logger.E("get bot %d error %v", to, err)
+		return false
+	}
+	if b == nil {
+		return false
+	}
+	submit(b, string(action), msg)
+	return true
+}
+
+// DeliverGroup 将群消息投递给群内的机器人, 没有读取所有消息权限的机器人只收到 @ 它的消息
+func DeliverGroup(botUid int64, readAll bool, msg *message.ChatMessage) {
+	b, err := GetBot(botUid)
+	if err != nil {
+		logger.E("get bot %d error %v", botUid, err)
+		return
+	}
+	if b == nil {
+		return
+	}
+	if !readAll && !Mentioned(msg.Content, b.Uid, b.Name) {
+		return
+	}
+	submit(b, string(message.ActionGroupMessage), msg)
+}
+
+// submit 有推送订阅时推送给机器人, 未启用推送或订阅不存在时保存为长轮询消息, 避免丢失
+func submit(b *botdao.Bot, action string, msg *message.ChatMessage) {
+	if b.WebhookID != 0 && webhook.PublishTo(b.WebhookID, action, msg) {
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		logger.E("encode bot update error %v", err)
+		return
+	}
+	err = execPool.Submit(func() {
+		u := &botdao.BotUpdate{
+			BotUid:   b.Uid,
+			Action:   action,
+			Payload:  string(payload),
+			CreateAt: time.Now().Unix(),
+		}
+		if err := botdao.Dao.AddUpdate(u); err != nil {
+			logger.E("save bot update error %v", err)
+			return
+		}
+		wake(b.Uid)
+	})
+	if err != nil {
+		logger.E("deliver message to bot %d error %v", b.Uid, err)
+	}
+}
+
+// GetUpdates 长轮询获取机器人的消息, 返回 ID 不小于 offset 的消息, 并删除 ID 小于 offset 的已确认消息,
+// 没有消息时最多等待 timeout
+func GetUpdates(botUid int64, offset int64, limit int, timeout time.Duration) ([]*Update, error) {
+	if offset > 0 {
+		if err := botdao.Dao.DeleteUpdates(botUid, offset); err != nil {
+			return nil, err
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		// 在查询前获取等待通道, 避免丢失查询后写入的消息的通知
+		ch := waitCh(botUid)
+		us, err := botdao.Dao.GetUpdates(botUid, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		remain := time.Until(deadline)
+		if len(us) > 0 || remain <= 0 {
+			//goland:noinspection GoPreferNilSlice
+			updates := []*Update{}
+			for _, u := range us {
+				updates = append(updates, &Update{
+					Id:      u.ID,
+					Action:  u.Action,
+					Message: json.RawMessage(u.Payload),
+				})
+			}
+			return updates, nil
+		}
+		if remain > pollInterval {
+			remain = pollInterval
+		}
+		select {
+		case <-ch:
+		case <-time.After(remain):
+		}
+	}
+}
+
+func waitCh(botUid int64) chan struct{} {
+	waitMu.Lock()
+	defer waitMu.Unlock()
+	ch, ok := waiters[botUid]
+	if !ok {
+		ch = make(chan struct{})
+		waiters[botUid] = ch
+	}
+	return ch
+}
+
+func wake(botUid int64) {
+	waitMu.Lock()
+	defer waitMu.Unlock()
+	if ch, ok := waiters[botUid]; ok {
+		close(ch)
+		delete(waiters, botUid)
+	}
+}
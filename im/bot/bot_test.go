@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMentioned(t *testing.T) {
+	cases := []struct {
+		content string
+		want    bool
+	}{
+		{"@1001 hello", true},
+		{"hello @1001", true},
+		{"@10012 hello", false},
+		{"@10012 @1001", true},
+		{"@weather today", true},
+		{"@weather, today", true},
+		{"@weather2 today", false},
+		{"@weathers today", false},
+		{"weather today", false},
+	}
+	for _, c := range cases {
+		if got := Mentioned(c.content, 1001, "weather"); got != c.want {
+			t.Errorf("Mentioned(%q) = %v, want %v", c.content, got, c.want)
+		}
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash := NewToken(1001)
+	if !strings.HasPrefix(token, "1001:") {
+		t.Errorf("token %s not prefixed with bot uid", token)
+	}
+	if hash != HashToken(token) {
+		t.Error("token hash mismatch")
+	}
+	other, _ := NewToken(1001)
+	if other == token {
+		t.Error("duplicate token")
+	}
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/glide-im/glideim/im/dao/botdao"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/im/dao/webhookdao"
+	"github.com/glide-im/glideim/im/webhook"
+	"github.com/glide-im/glideim/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// SetWebhook 设置机器人的推送地址, url 为空时删除推送订阅, 改为长轮询接收消息, 新建订阅时返回签名密钥.
+// 机器人的订阅不订阅任何事件, 只接收发给该机器人的消息, 调用者需要保存机器人的 WebhookID
+func SetWebhook(b *botdao.Bot, url string) (string, error) {
+	secret := ""
+	defer reloadWebhook()
+
+	if url == "" {
+		if b.WebhookID != 0 {
+			err := webhookdao.Dao.DeleteWebhook(b.WebhookID)
+			if err != nil && err != common.ErrNoneUpdated {
+				return "", err
+			}
+			b.WebhookID = 0
+		}
+		return secret, nil
+	}
+	if b.WebhookID != 0 {
+		w, err := webhookdao.Dao.GetWebhook(b.WebhookID)
+		if err == nil {
+			w.Url = url
+			return secret, webhookdao.Dao.UpdateWebhook(w)
+		}
+		if err != common.ErrNoRecordFound {
+			return "", err
+		}
+	}
+	now := time.Now().Unix()
+	s := make([]byte, 24)
+	_, _ = rand.Read(s)
+	secret = hex.EncodeToString(s)
+	w := &webhookdao.Webhook{
+		Url:         url,
+		Secret:      secret,
+		Description: "bot " + strconv.FormatInt(b.Uid, 10),
+		Enabled:     true,
+		Bot:         true,
+		CreateAt:    now,
+		UpdateAt:    now,
+	}
+	if err := webhookdao.Dao.CreateWebhook(w); err != nil {
+		return "", err
+	}
+	b.WebhookID = w.ID
+	return secret, nil
+}
+
+// GetWebhookUrl 获取机器人的推送地址, 没有推送订阅时返回空字符串
+func GetWebhookUrl(b *botdao.Bot) (string, error) {
+	if b.WebhookID == 0 {
+		return "", nil
+	}
+	w, err := webhookdao.Dao.GetWebhook(b.WebhookID)
+	if err == common.ErrNoRecordFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return w.Url, nil
+}
+
+func reloadWebhook() {
+	if err := webhook.Reload(); err != nil {
+		logger.E("reload webhook subscriptions error %v", err)
+	}
+}
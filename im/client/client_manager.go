@@ -83,7 +83,7 @@ func (c *DefaultClientManager) ClientSignIn(id, uid_ int64, device int64) error
 		existing := logged.get(device)
 		if existing != nil {
 			existing.SetID(uid.GenTemp(), 0)
-			_ = c.EnqueueMessage(uid_, device, message.NewMessage(0, message.ActionNotifyKickOut, ""))
+			_ = c.EnqueueMessage(uid_, device, false, message.NewMessage(0, message.ActionNotifyKickOut, ""))
 			existing.Exit()
 			logged.remove(device)
 		}
@@ -126,8 +126,8 @@ func (c *DefaultClientManager) ClientLogout(uid_ int64, device int64) error {
 	return nil
 }
 
-// EnqueueMessage to the client with the specified uid and device, device: pass 0 express all device.
-func (c *DefaultClientManager) EnqueueMessage(uid int64, device int64, msg *message.Message) error {
+// EnqueueMessage to the client with the specified uid and device, device: pass 0 express all device, exclude: deliver to all device except the specified one.
+func (c *DefaultClientManager) EnqueueMessage(uid int64, device int64, exclude bool, msg *message.Message) error {
 	atomic.AddInt64(&c.messageSent, 1)
 
 	var err error = nil
@@ -135,7 +135,7 @@ func (c *DefaultClientManager) EnqueueMessage(uid int64, device int64, msg *mess
 	if ds == nil || ds.size() == 0 {
 		return ErrClientNotExist
 	}
-	if device != 0 {
+	if device > 0 && !exclude {
 		d := ds.get(device)
 		if d == nil {
 			return ErrClientNotExist
@@ -143,7 +143,7 @@ func (c *DefaultClientManager) EnqueueMessage(uid int64, device int64, msg *mess
 		return c.enqueueMessage(d, msg)
 	}
 	ds.foreach(func(deviceId int64, cli IClient) {
-		if device != 0 && deviceId != device {
+		if exclude && deviceId == device {
 			return
 		}
 		err = c.enqueueMessage(cli, msg)
@@ -165,7 +165,7 @@ func (c *DefaultClientManager) enqueueMessage(cli IClient, msg *message.Message)
 	return nil
 }
 
-func (c *DefaultClientManager) isOnline(uid int64) bool {
+func (c *DefaultClientManager) IsOnline(uid int64) bool {
 	ds := c.clients.get(uid)
 	if ds == nil {
 		return false
@@ -173,6 +173,16 @@ func (c *DefaultClientManager) isOnline(uid int64) bool {
 	return ds.size() > 0
 }
 
+func (c *DefaultClientManager) OnlineUsers(uid ...int64) map[int64]bool {
+	online := map[int64]bool{}
+	for _, u := range uid {
+		if c.IsOnline(u) {
+			online[u] = true
+		}
+	}
+	return online
+}
+
 func (c *DefaultClientManager) isDeviceOnline(uid, device int64) bool {
 	ds := c.clients.get(uid)
 	if ds == nil {
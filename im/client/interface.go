@@ -20,7 +20,8 @@ type Interface interface {
 
 	ClientLogout(uid int64, device int64) error
 
-	EnqueueMessage(uid int64, device int64, message *message.Message) error
+	// EnqueueMessage 投递消息给用户的设备, device 为 0 时投递给所有设备, exclude 为 true 时投递给除 device 以外的所有设备
+	EnqueueMessage(uid int64, device int64, exclude bool, message *message.Message) error
 }
 
 type MessageHandler func(from int64, device int64, message *message.Message) error
@@ -37,27 +38,80 @@ func SignIn(oldUid int64, uid int64, device int64) error {
 func Logout(uid int64, device int64) error {
 	return manager.ClientLogout(uid, device)
 }
+
+// Presence 查询用户是否有在线的设备, 本地客户端管理直接查询, 客户端管理不在本节点时通过 rpc 查询网关,
+// 查询失败时视为不在线, 以免漏掉离线推送
+type Presence interface {
+	IsOnline(uid int64) bool
+	// OnlineUsers 批量查询, 返回有在线设备的用户
+	OnlineUsers(uid ...int64) map[int64]bool
+}
+
+// presence 客户端管理没有实现 Presence 时使用的在线状态查询
+var presence Presence = nil
+
+// devicePresence 可以查询设备在线状态的客户端管理实现, 本地客户端管理实现该接口
+type devicePresence interface {
+	isDeviceOnline(uid, device int64) bool
+}
+
 func IsDeviceOnline(uid, device int64) bool {
+	if p, ok := manager.(devicePresence); ok {
+		return p.isDeviceOnline(uid, device)
+	}
 	return false
 }
+
+// IsOnline 用户是否有在线的设备, 客户端管理和 SetPresenceImpl 都无法查询时视为在线
 func IsOnline(uid int64) bool {
+	if p, ok := manager.(Presence); ok {
+		return p.IsOnline(uid)
+	}
+	if presence != nil {
+		return presence.IsOnline(uid)
+	}
 	return true
 }
 
+// OnlineUsers 批量查询有在线设备的用户, 用于群消息离线推送等需要查询大量用户的场景, 无法查询时视为都在线
+func OnlineUsers(uid ...int64) map[int64]bool {
+	if p, ok := manager.(Presence); ok {
+		return p.OnlineUsers(uid...)
+	}
+	if presence != nil {
+		return presence.OnlineUsers(uid...)
+	}
+	online := map[int64]bool{}
+	for _, u := range uid {
+		online[u] = true
+	}
+	return online
+}
+
 // EnqueueMessage Manager.EnqueueMessage 的快捷方法, 预留一个位置对消息入队列进行一些预处理
 func EnqueueMessage(uid int64, message *message.Message) error {
 	//
-	return manager.EnqueueMessage(uid, 0, message)
+	return manager.EnqueueMessage(uid, 0, false, message)
 }
 
 func EnqueueMessageToDevice(uid int64, device int64, message *message.Message) error {
-	return manager.EnqueueMessage(uid, device, message)
+	return manager.EnqueueMessage(uid, device, false, message)
+}
+
+// EnqueueMessageToOtherDevices 投递消息给用户除 device 以外的所有设备, device 为 0 时投递给所有设备
+func EnqueueMessageToOtherDevices(uid int64, device int64, message *message.Message) error {
+	return manager.EnqueueMessage(uid, device, true, message)
 }
 
 func SetInterfaceImpl(i Interface) {
 	manager = i
 }
 
+// SetPresenceImpl 设置在线状态查询, 用于客户端管理实现无法查询在线状态的节点, 例如通过 dispatch 投递消息的节点
+func SetPresenceImpl(p Presence) {
+	presence = p
+}
+
 func SetMessageHandler(handler MessageHandler) {
 	messageHandleFunc = func(from int64, device int64, message *message.Message) error {
 		err := handler(from, device, message)
@@ -0,0 +1,118 @@
+package botdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"time"
+)
+
+var Dao BotDao = botDaoImpl{}
+
+type BotDao interface {
+	CreateBot(b *Bot) error
+	GetBot(uid int64) (*Bot, error)
+	GetBotByToken(tokenHash string) (*Bot, error)
+	GetBotsByOwner(owner int64) ([]*Bot, error)
+	// UpdateBot 更新机器人的名称, 头像和推送订阅
+	UpdateBot(b *Bot) error
+	UpdateToken(uid int64, tokenHash string) error
+	DeleteBot(uid int64) error
+
+	AddUpdate(u *BotUpdate) error
+	// GetUpdates 按 ID 升序获取 ID 不小于 offset 的消息
+	GetUpdates(botUid int64, offset int64, limit int) ([]*BotUpdate, error)
+	// DeleteUpdates 删除 ID 小于 offset 的消息, 即机器人已确认的消息
+	DeleteUpdates(botUid int64, offset int64) error
+}
+
+type botDaoImpl struct {
+}
+
+func (botDaoImpl) CreateBot(b *Bot) error {
+	query := db.DB.Create(b)
+	return common.ResolveError(query)
+}
+
+func (botDaoImpl) GetBot(uid int64) (*Bot, error) {
+	b := &Bot{}
+	query := db.DB.Model(b).Where("`uid` = ?", uid).Find(b)
+	if err := common.MustFind(query); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (botDaoImpl) GetBotByToken(tokenHash string) (*Bot, error) {
+	b := &Bot{}
+	query := db.DB.Model(b).Where("`token_hash` = ?", tokenHash).Find(b)
+	if err := common.MustFind(query); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (botDaoImpl) GetBotsByOwner(owner int64) ([]*Bot, error) {
+	//goland:noinspection GoPreferNilSlice
+	bs := []*Bot{}
+	query := db.DB.Model(&Bot{}).Where("`owner` = ?", owner).Order("`uid` ASC").Find(&bs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+func (botDaoImpl) UpdateBot(b *Bot) error {
+	b.UpdateAt = time.Now().Unix()
+	query := db.DB.Model(&Bot{}).
+		Where("`uid` = ?", b.Uid).
+		Updates(map[string]interface{}{
+			"name":       b.Name,
+			"avatar":     b.Avatar,
+			"webhook_id": b.WebhookID,
+			"update_at":  b.UpdateAt,
+		})
+	return common.JustError(query)
+}
+
+func (botDaoImpl) UpdateToken(uid int64, tokenHash string) error {
+	query := db.DB.Model(&Bot{}).
+		Where("`uid` = ?", uid).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"update_at":  time.Now().Unix(),
+		})
+	return common.MustUpdate(query)
+}
+
+func (botDaoImpl) DeleteBot(uid int64) error {
+	query := db.DB.Where("`uid` = ?", uid).Delete(&Bot{})
+	if err := common.MustUpdate(query); err != nil {
+		return err
+	}
+	query = db.DB.Where("`bot_uid` = ?", uid).Delete(&BotUpdate{})
+	return common.JustError(query)
+}
+
+func (botDaoImpl) AddUpdate(u *BotUpdate) error {
+	query := db.DB.Create(u)
+	return common.ResolveError(query)
+}
+
+func (botDaoImpl) GetUpdates(botUid int64, offset int64, limit int) ([]*BotUpdate, error) {
+	//goland:noinspection GoPreferNilSlice
+	us := []*BotUpdate{}
+	query := db.DB.Model(&BotUpdate{}).
+		Where("`bot_uid` = ? AND `id` >= ?", botUid, offset).
+		Order("`id` ASC").
+		Limit(limit).
+		Find(&us)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+func (botDaoImpl) DeleteUpdates(botUid int64, offset int64) error {
+	query := db.DB.Where("`bot_uid` = ? AND `id` < ?", botUid, offset).Delete(&BotUpdate{})
+	return common.JustError(query)
+}
@@ -0,0 +1,28 @@
+package botdao
+
+import (
+	"github.com/glide-im/glideim/pkg/db"
+	"testing"
+	"time"
+)
+
+func init() {
+	db.Init()
+}
+
+func TestBotDao_AddUpdate(t *testing.T) {
+	err := Dao.AddUpdate(&BotUpdate{
+		BotUid:   1001,
+		Action:   "message.chat",
+		Payload:  "{}",
+		CreateAt: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	us, err := Dao.GetUpdates(1001, 0, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(us)
+}
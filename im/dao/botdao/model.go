@@ -0,0 +1,25 @@
+package botdao
+
+// Bot 机器人账号, Uid 在系统 ID 范围内分配
+type Bot struct {
+	Uid    int64 `gorm:"primaryKey"`
+	Owner  int64
+	Name   string
+	Avatar string
+	// TokenHash 机器人 token 的 SHA-256, token 只在创建和重置时返回
+	TokenHash string
+	// WebhookID 不为 0 时消息通过该推送订阅推送给机器人, 否则由机器人长轮询拉取
+	WebhookID int64
+	CreateAt  int64
+	UpdateAt  int64
+}
+
+// BotUpdate 等待机器人长轮询拉取的消息
+type BotUpdate struct {
+	ID     int64 `gorm:"primaryKey"`
+	BotUid int64
+	Action string
+	// Payload 消息数据
+	Payload  string
+	CreateAt int64
+}
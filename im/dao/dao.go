@@ -1,9 +1,11 @@
 package dao
 
 import (
+	"github.com/glide-im/glideim/im/dao/mid"
 	"github.com/glide-im/glideim/im/dao/uid"
 )
 
 func Init() {
 	uid.Init()
+	mid.Init()
 }
@@ -64,6 +64,10 @@ func (g *GroupInfoDaoImpl) UpdateGroupFlag(gid int64, flag int) error {
 	return g.updateGroupField(gid, "flag", flag)
 }
 
+func (g *GroupInfoDaoImpl) UpdateGroupMsgTTL(gid int64, ttl int64) error {
+	return g.updateGroupField(gid, "msg_ttl", ttl)
+}
+
 func (GroupInfoDaoImpl) GetGroupMute(gid int64) (bool, error) {
 	model := &GroupModel{}
 	var mute bool
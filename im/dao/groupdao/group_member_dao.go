@@ -10,8 +10,14 @@ const (
 	GroupMemberTypeOwner = 1
 	GroupMemberTypeAdmin = 2
 	GroupMemberNormal    = 3
+	GroupMemberTypeBot   = 4
 
 	GroupFlagDefault = 0
+
+	// GroupMemberFlagMuted 成员被禁言
+	GroupMemberFlagMuted = 1
+	// GroupMemberFlagBotReadAll 机器人可以读取群内所有消息, 否则只能收到 @ 它的消息
+	GroupMemberFlagBotReadAll = 1 << 1
 )
 
 type GroupMemberDaoImpl struct {
@@ -26,6 +32,15 @@ func (GroupMemberDaoImpl) GetMembers(gid int64) ([]*GroupMemberModel, error) {
 	return gms, nil
 }
 
+func (GroupMemberDaoImpl) GetMemberGroups(uid int64) ([]int64, error) {
+	var gid []int64
+	query := db.DB.Model(&GroupMemberModel{}).Where("uid = ?", uid).Pluck("gid", &gid)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return gid, nil
+}
+
 func GetMemberID(gid int64, uid int64) string {
 	return strconv.FormatInt(gid, 10) + "_" + strconv.FormatInt(uid, 10)
 }
@@ -15,6 +15,7 @@ type GroupInfoDao interface {
 	UpdateGroupMute(gid int64, mute bool) error
 	GetGroupMute(gid int64) (bool, error)
 	UpdateGroupFlag(gid int64, flag int) error
+	UpdateGroupMsgTTL(gid int64, ttl int64) error
 	GetGroupFlag(gid int64) (int, error)
 	HasGroup(gid int64) (bool, error)
 }
@@ -22,6 +23,8 @@ type GroupInfoDao interface {
 type GroupMemberDao interface {
 	HasMember(gid int64, uid int64) (bool, error)
 	GetMembers(gid int64) ([]*GroupMemberModel, error)
+	// GetMemberGroups 获取用户加入的所有群 ID
+	GetMemberGroups(uid int64) ([]int64, error)
 	AddMember(gid int64, uid int64, typ int64, defaultFlag int64) error
 	AddMembers(gid int64, flag int64, typ int64, uid ...int64) error
 	RemoveMember(gid int64, uid int64) error
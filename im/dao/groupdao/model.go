@@ -3,11 +3,13 @@ package groupdao
 import "github.com/glide-im/glideim/im/dao/common"
 
 type GroupModel struct {
-	Gid      int64 `gorm:"primaryKey"`
-	Name     string
-	Avatar   string
-	Mute     bool
-	Flag     int
+	Gid    int64 `gorm:"primaryKey"`
+	Name   string
+	Avatar string
+	Mute   bool
+	Flag   int
+	// MsgTTL 群消息存活时间, 单位秒, 0 表示消息不过期
+	MsgTTL   int64
 	CreateAt int64
 }
 
@@ -0,0 +1,47 @@
+package idgen
+
+import (
+	"testing"
+)
+
+func TestSnowflake_NextID(t *testing.T) {
+	s, err := NewSnowflake(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last int64
+	for i := 0; i < 100000; i++ {
+		id, err := s.NextID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("id not increasing, last=%d, id=%d", last, id)
+		}
+		if (id>>snowflakeSeqBits)&MaxSnowflakeNode != 1 {
+			t.Fatalf("unexpected node in id %d", id)
+		}
+		last = id
+	}
+}
+
+func TestSnowflake_ClockBackwards(t *testing.T) {
+	s, _ := NewSnowflake(0)
+	now := int64(snowflakeEpoch + 1000)
+	s.now = func() int64 {
+		return now
+	}
+	if _, err := s.NextID(); err != nil {
+		t.Fatal(err)
+	}
+	now--
+	if _, err := s.NextID(); err != ErrClockBackwards {
+		t.Fatalf("expect ErrClockBackwards, got %v", err)
+	}
+}
+
+func TestNewSnowflake_InvalidNode(t *testing.T) {
+	if _, err := NewSnowflake(MaxSnowflakeNode + 1); err == nil {
+		t.Fatal("expect error for invalid node")
+	}
+}
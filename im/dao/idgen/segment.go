@@ -0,0 +1,132 @@
+package idgen
+
+import (
+	"github.com/glide-im/glideim/pkg/logger"
+	"sync"
+)
+
+// SegmentStore 号段存储, 每个业务标识一条记录, 记录当前已租出的最大 ID
+type SegmentStore interface {
+	// Init 创建号段记录, 记录已存在时只在其最大 ID 小于 maxId 时增大, 不会减小
+	Init(tag string, maxId int64) error
+	// Lease 租用下一个号段, 返回租用后的最大 ID, 租到的号段为 (maxId-step, maxId]
+	Lease(tag string, step int64) (int64, error)
+	// Reset 仅当当前最大 ID 为 expect 时将其重置为 maxId, 用于循环使用的 ID 范围
+	Reset(tag string, expect int64, maxId int64) (bool, error)
+}
+
+type segment struct {
+	min int64
+	max int64
+}
+
+// SegmentAllocator 号段 ID 分配器, 每次从存储中批量租用 step 个 ID 在本地分配,
+// 当前号段剩余不足 20% 时异步预取下一个号段, 分配时不需要访问存储.
+// 分配的 ID 在 [start, end) 范围内, cycle 为 true 时用尽后从 start 重新开始, 否则返回 ErrExhausted.
+type SegmentAllocator struct {
+	store SegmentStore
+	tag   string
+	start int64
+	end   int64
+	step  int64
+	cycle bool
+
+	mu      sync.Mutex
+	cur     int64
+	max     int64
+	next    *segment
+	loading chan struct{}
+}
+
+func NewSegmentAllocator(store SegmentStore, tag string, start int64, end int64, step int64, cycle bool) (*SegmentAllocator, error) {
+	err := store.Init(tag, start-1)
+	if err != nil {
+		return nil, err
+	}
+	return &SegmentAllocator{
+		store: store,
+		tag:   tag,
+		start: start,
+		end:   end,
+		step:  step,
+		cycle: cycle,
+		cur:   1,
+		max:   0,
+	}, nil
+}
+
+func (a *SegmentAllocator) NextID() (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for a.cur > a.max {
+		if a.next != nil {
+			a.cur, a.max = a.next.min, a.next.max
+			a.next = nil
+			break
+		}
+		if a.loading != nil {
+			// 等待正在进行的预取, 保证号段按租用顺序使用
+			loading := a.loading
+			a.mu.Unlock()
+			<-loading
+			a.mu.Lock()
+			continue
+		}
+		seg, err := a.lease()
+		if err != nil {
+			return 0, err
+		}
+		a.cur, a.max = seg.min, seg.max
+	}
+
+	id := a.cur
+	a.cur++
+	if a.next == nil && a.loading == nil && a.max-a.cur < a.step/5 {
+		a.loading = make(chan struct{})
+		go a.prefetch(a.loading)
+	}
+	return id, nil
+}
+
+func (a *SegmentAllocator) prefetch(done chan struct{}) {
+	seg, err := a.lease()
+
+	a.mu.Lock()
+	if err != nil {
+		logger.E("prefetch id segment %s error %v", a.tag, err)
+	} else {
+		a.next = &seg
+	}
+	a.loading = nil
+	a.mu.Unlock()
+	close(done)
+}
+
+func (a *SegmentAllocator) lease() (segment, error) {
+	for i := 0; i < 3; i++ {
+		max, err := a.store.Lease(a.tag, a.step)
+		if err != nil {
+			return segment{}, err
+		}
+		seg := segment{min: max - a.step + 1, max: max}
+		if seg.min < a.start {
+			seg.min = a.start
+		}
+		if seg.max >= a.end {
+			seg.max = a.end - 1
+		}
+		if seg.min <= seg.max {
+			return seg, nil
+		}
+		if !a.cycle {
+			return segment{}, ErrExhausted
+		}
+		// 范围已用尽, 重置后重新租用, 其他节点已经重置时这里不会生效
+		_, err = a.store.Reset(a.tag, max, a.start-1)
+		if err != nil {
+			return segment{}, err
+		}
+	}
+	return segment{}, ErrExhausted
+}
@@ -0,0 +1,80 @@
+package idgen
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// IdSegment 号段记录, MaxID 为已租出的最大 ID
+type IdSegment struct {
+	BizTag   string `gorm:"primaryKey"`
+	MaxID    int64
+	UpdateAt int64
+}
+
+var MySqlSegmentStore SegmentStore = mysqlSegmentStore{}
+
+type mysqlSegmentStore struct {
+}
+
+func (mysqlSegmentStore) Init(tag string, maxId int64) error {
+	s := &IdSegment{
+		BizTag:   tag,
+		MaxID:    maxId,
+		UpdateAt: time.Now().Unix(),
+	}
+	query := db.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"max_id": gorm.Expr("GREATEST(`max_id`, VALUES(`max_id`))"),
+		}),
+	}).Create(s)
+	return common.JustError(query)
+}
+
+// MaxTableID 查询表中 [start, end) 范围内已保存的最大 ID, 没有记录时返回 0, 用于号段记录避开已分配的 ID
+func MaxTableID(table string, column string, start int64, end int64) (int64, error) {
+	var max int64
+	err := db.DB.Table(table).
+		Select("IFNULL(MAX(`"+column+"`), 0)").
+		Where("`"+column+"` >= ? AND `"+column+"` < ?", start, end).
+		Row().
+		Scan(&max)
+	return max, err
+}
+
+func (mysqlSegmentStore) Lease(tag string, step int64) (int64, error) {
+	var maxId int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&IdSegment{}).
+			Where("`biz_tag` = ?", tag).
+			Updates(map[string]interface{}{
+				"max_id":    gorm.Expr("`max_id` + ?", step),
+				"update_at": time.Now().Unix(),
+			})
+		if err := common.MustUpdate(query); err != nil {
+			return err
+		}
+		return tx.Model(&IdSegment{}).
+			Select("max_id").
+			Where("`biz_tag` = ?", tag).
+			Row().
+			Scan(&maxId)
+	})
+	return maxId, err
+}
+
+func (mysqlSegmentStore) Reset(tag string, expect int64, maxId int64) (bool, error) {
+	query := db.DB.Model(&IdSegment{}).
+		Where("`biz_tag` = ? AND `max_id` = ?", tag, expect).
+		Updates(map[string]interface{}{
+			"max_id":    maxId,
+			"update_at": time.Now().Unix(),
+		})
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
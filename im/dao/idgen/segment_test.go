@@ -0,0 +1,156 @@
+package idgen
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type mockSegmentStore struct {
+	mu  sync.Mutex
+	max map[string]int64
+}
+
+func newMockSegmentStore() *mockSegmentStore {
+	return &mockSegmentStore{max: map[string]int64{}}
+}
+
+func (m *mockSegmentStore) Init(tag string, maxId int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max, ok := m.max[tag]; !ok || max < maxId {
+		m.max[tag] = maxId
+	}
+	return nil
+}
+
+func (m *mockSegmentStore) Lease(tag string, step int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	max, ok := m.max[tag]
+	if !ok {
+		return 0, errors.New("segment not exist")
+	}
+	m.max[tag] = max + step
+	return max + step, nil
+}
+
+func (m *mockSegmentStore) Reset(tag string, expect int64, maxId int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.max[tag] != expect {
+		return false, nil
+	}
+	m.max[tag] = maxId
+	return true, nil
+}
+
+func TestSegmentAllocator_NextID(t *testing.T) {
+	a, err := NewSegmentAllocator(newMockSegmentStore(), "test", 100, 10000, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(100); i < 1000; i++ {
+		id, err := a.NextID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i {
+			t.Fatalf("expect id %d, got %d", i, id)
+		}
+	}
+}
+
+func TestSegmentAllocator_Concurrent(t *testing.T) {
+	store := newMockSegmentStore()
+	var allocators []*SegmentAllocator
+	for i := 0; i < 3; i++ {
+		a, err := NewSegmentAllocator(store, "test", 1, 1<<40, 50, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allocators = append(allocators, a)
+	}
+
+	mu := sync.Mutex{}
+	ids := map[int64]bool{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		a := allocators[i%len(allocators)]
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				id, err := a.NextID()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if ids[id] {
+					t.Errorf("duplicate id %d", id)
+				}
+				ids[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(ids) != 30*500 {
+		t.Errorf("expect %d ids, got %d", 30*500, len(ids))
+	}
+}
+
+func TestSegmentAllocator_Exhausted(t *testing.T) {
+	a, err := NewSegmentAllocator(newMockSegmentStore(), "test", 1, 16, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i < 16; i++ {
+		id, err := a.NextID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i {
+			t.Fatalf("expect id %d, got %d", i, id)
+		}
+	}
+	_, err = a.NextID()
+	if err != ErrExhausted {
+		t.Fatalf("expect ErrExhausted, got %v", err)
+	}
+}
+
+func TestSegmentAllocator_Cycle(t *testing.T) {
+	a, err := NewSegmentAllocator(newMockSegmentStore(), "test", 1, 16, 10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 40; i++ {
+		id, err := a.NextID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id < 1 || id >= 16 {
+			t.Fatalf("id %d out of range", id)
+		}
+	}
+}
+
+func TestSegmentAllocator_Seed(t *testing.T) {
+	store := newMockSegmentStore()
+	// 已保存的最大 ID 之后开始分配, 之后再次初始化不会回退
+	_ = store.Init("test", 500)
+	_ = store.Init("test", 200)
+	a, err := NewSegmentAllocator(store, "test", 100, 10000, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := a.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 501 {
+		t.Errorf("expect id 501, got %d", id)
+	}
+}
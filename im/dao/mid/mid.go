@@ -1,14 +1,70 @@
 package mid
 
 import (
+	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/idgen"
 	"github.com/glide-im/glideim/pkg/db"
+	"math"
 )
 
+const (
+	keyMidIncr       = "im:msg:mid:incr"
+	keyMessageIdIncr = "im:msg:id:incr"
+
+	segmentTagMid = "mid"
+)
+
+var errNotInitialized = errors.New("message id allocator is not initialized")
+
+// allocator 消息 ID 分配器, 由 Init 根据配置创建, 未初始化时不分配, 避免与其他节点使用不同的分配方式
+var allocator idgen.Allocator
+
+// Init 根据配置创建消息 ID 分配器
+func Init() {
+	switch config.IdGen.MidMode {
+	case config.IdGenModeRedis:
+		allocator = idgen.NewRedisAllocator(keyMidIncr)
+	case config.IdGenModeSnowflake:
+		s, err := idgen.NewSnowflake(config.IdGen.NodeId)
+		if err != nil {
+			panic(err)
+		}
+		allocator = s
+	default:
+		// 号段记录从 Redis 中原有的计数和消息表中已保存的最大 ID 之后开始, 避免与已分配的 ID 重复
+		var seed int64
+		for _, k := range []string{keyMidIncr, keyMessageIdIncr} {
+			n, err := db.Redis.Get(k).Int64()
+			if err == nil && n > seed {
+				seed = n
+			}
+		}
+		for _, t := range []string{"im_chat_message", "im_group_message"} {
+			n, err := idgen.MaxTableID(t, "m_id", 1, math.MaxInt64)
+			if err != nil {
+				panic(err)
+			}
+			if n > seed {
+				seed = n
+			}
+		}
+		if seed > 0 {
+			if err := idgen.MySqlSegmentStore.Init(segmentTagMid, seed); err != nil {
+				panic(err)
+			}
+		}
+		a, err := idgen.NewSegmentAllocator(idgen.MySqlSegmentStore, segmentTagMid, 1, math.MaxInt64, config.IdGen.SegmentStep, false)
+		if err != nil {
+			panic(err)
+		}
+		allocator = a
+	}
+}
+
 func GetMid() (int64, error) {
-	k := "im:msg:mid:incr"
-	seq, err := db.Redis.Incr(k).Result()
-	if err != nil {
-		return 0, err
+	if allocator == nil {
+		return 0, errNotInitialized
 	}
-	return seq, nil
+	return allocator.NextID()
 }
@@ -2,13 +2,16 @@ package msgdao
 
 import (
 	"github.com/glide-im/glideim/pkg/db"
+	"github.com/go-redis/redis"
 	"strconv"
+	"time"
 )
 
 var Cache = cacheDao{}
 
 const (
 	keyUserMsgSeq = "im:msg:seq:"
+	keyMsgSend    = "im:msg:send:"
 )
 
 type cacheDao struct{}
@@ -35,3 +38,29 @@ func (cacheDao) GetIncrUserMsgSeq(uid int64) (int64, error) {
 	}
 	return seq, nil
 }
+
+func sendKey(from int64, device int64, cliSeq int64) string {
+	return keyMsgSend + strconv.FormatInt(from, 10) + "_" + strconv.FormatInt(device, 10) + "_" + strconv.FormatInt(cliSeq, 10)
+}
+
+func (cacheDao) ClaimSend(from int64, device int64, cliSeq int64, window time.Duration) (bool, error) {
+	return db.Redis.SetNX(sendKey(from, device, cliSeq), 0, window).Result()
+}
+
+func (cacheDao) CompleteSend(from int64, device int64, cliSeq int64, mid int64, window time.Duration) error {
+	_, err := db.Redis.Set(sendKey(from, device, cliSeq), mid, window).Result()
+	return err
+}
+
+func (cacheDao) GetSendResult(from int64, device int64, cliSeq int64) (int64, error) {
+	mid, err := db.Redis.Get(sendKey(from, device, cliSeq)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return mid, err
+}
+
+func (cacheDao) ReleaseSend(from int64, device int64, cliSeq int64) error {
+	_, err := db.Redis.Del(sendKey(from, device, cliSeq)).Result()
+	return err
+}
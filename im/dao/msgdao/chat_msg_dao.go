@@ -3,7 +3,10 @@ package msgdao
 import (
 	"github.com/glide-im/glideim/im/dao/common"
 	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strconv"
+	"time"
 )
 
 var ChatMsgDaoImpl ChatMsgDao = chatMsgDaoImpl{}
@@ -22,6 +25,7 @@ func (chatMsgDaoImpl) GetRecentChatMessagesBySession(uid1, uid2 int64, pageSize
 	var ms []*ChatMessage
 	query := db.DB.Model(&ChatMessage{}).
 		Where("`session_id` = ?", sid).
+		Scopes(visibleChatMessages(uid1)).
 		Order("`send_at` DESC").
 		Limit(pageSize).
 		Find(&ms)
@@ -36,6 +40,7 @@ func (chatMsgDaoImpl) GetChatMessagesBySession(uid1, uid2 int64, beforeMid int64
 	var ms []*ChatMessage
 	query := db.DB.Model(&ChatMessage{}).
 		Where("`session_id` = ? AND `m_id` < ?", sid, beforeMid).
+		Scopes(visibleChatMessages(uid1)).
 		Order("`send_at` DESC").
 		Limit(pageSize).
 		Find(&ms)
@@ -47,7 +52,10 @@ func (chatMsgDaoImpl) GetChatMessagesBySession(uid1, uid2 int64, beforeMid int64
 
 func (chatMsgDaoImpl) GetRecentChatMessages(uid int64, after int64) ([]*ChatMessage, error) {
 	var ms []*ChatMessage
-	query := db.DB.Model(&ChatMessage{}).Where("`from` = ? OR `to` = ? AND `send_at` > ?", uid, uid, after).Find(&ms)
+	query := db.DB.Model(&ChatMessage{}).
+		Where("`from` = ? OR `to` = ? AND `send_at` > ?", uid, uid, after).
+		Scopes(visibleChatMessages(uid)).
+		Find(&ms)
 	if query.Error != nil {
 		return nil, query.Error
 	}
@@ -64,10 +72,42 @@ func (chatMsgDaoImpl) GetChatMessage(mid ...int64) ([]*ChatMessage, error) {
 	return m, nil
 }
 
+func (chatMsgDaoImpl) GetVisibleChatMessages(uid int64, mid ...int64) ([]*ChatMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	m := []*ChatMessage{}
+	if len(mid) == 0 {
+		return m, nil
+	}
+	query := db.DB.Model(&ChatMessage{}).
+		Where("`m_id` IN (?)", mid).
+		Scopes(visibleChatMessages(uid)).
+		Find(&m)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (chatMsgDaoImpl) UpdateChatMessageStatus(mid int64, from, to int64, status int) error {
 	u := ChatMessage{}
 	update := db.DB.Model(&u).Where("`m_id` = ? AND `from` = ? AND `to` = ?", mid, from, to).UpdateColumn("status", status)
-	return common.MustUpdate(update)
+	if err := common.MustUpdate(update); err != nil {
+		return err
+	}
+	if status == ChatMessageStatusRecalled {
+		removeIndex(mid)
+	}
+	return nil
+}
+
+func (chatMsgDaoImpl) EditChatMessage(mid int64, from int64, content string, editAt int64) error {
+	where := "`m_id` = ? AND `from` = ? AND `status` = ?"
+	err := editMessage(&ChatMessage{}, where, mid, content, editAt, mid, from, ChatMessageStatusDefault)
+	if err != nil {
+		return err
+	}
+	indexMessage(mid, false, content)
+	return nil
 }
 
 func (chatMsgDaoImpl) AddChatMessage(message *ChatMessage) (bool, error) {
@@ -83,6 +123,7 @@ func (chatMsgDaoImpl) AddChatMessage(message *ChatMessage) (bool, error) {
 	if err := common.ResolveError(query); err != nil {
 		return false, err
 	}
+	indexMessage(message.MID, false, message.Content)
 	return true, nil
 }
 
@@ -114,18 +155,37 @@ func (chatMsgDaoImpl) GetChatMessageMidSpan(from, to int64, midStart, midEnd int
 	return ms, nil
 }
 
+func (chatMsgDaoImpl) HasChatMessageFrom(from int64, to int64) (bool, error) {
+	sid, _, _ := getSessionId(from, to)
+	var mid []int64
+	query := db.DB.Model(&ChatMessage{}).
+		Where("`session_id` = ? AND `from` = ?", sid, from).
+		Limit(1).
+		Pluck("m_id", &mid)
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return len(mid) > 0, nil
+}
+
 func (chatMsgDaoImpl) AddOfflineMessage(uid int64, mid int64) error {
 	offlineMessage := &OfflineMessage{
-		MID: mid,
-		UID: uid,
+		MID:      mid,
+		UID:      uid,
+		CreateAt: time.Now().Unix(),
 	}
 	query := db.DB.Create(offlineMessage)
 	return common.ResolveError(query)
 }
 
-func (chatMsgDaoImpl) GetOfflineMessage(uid int64) ([]*OfflineMessage, error) {
-	var m []*OfflineMessage
-	query := db.DB.Model(&OfflineMessage{}).Where("uid = ?", uid).Find(&m)
+func (chatMsgDaoImpl) GetOfflineMessage(uid int64, cursor int64, since int64, limit int) ([]*OfflineMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	m := []*OfflineMessage{}
+	query := db.DB.Model(&OfflineMessage{}).
+		Where("uid = ? AND id > ? AND create_at >= ?", uid, cursor, since).
+		Order("id ASC").
+		Limit(limit).
+		Find(&m)
 	if query.Error != nil {
 		return nil, query.Error
 	}
@@ -136,3 +196,74 @@ func (chatMsgDaoImpl) DelOfflineMessage(uid int64, mid []int64) error {
 	query := db.DB.Where("uid = ? AND m_id IN (?)", uid, mid).Delete(&OfflineMessage{})
 	return query.Error
 }
+
+func (chatMsgDaoImpl) AckOfflineMessage(uid int64, cursor int64) error {
+	query := db.DB.Where("uid = ? AND id <= ?", uid, cursor).Delete(&OfflineMessage{})
+	if err := common.JustError(query); err != nil {
+		return err
+	}
+	// 确认到最后一条离线消息时才清零, 部分确认时客户端仍需要知道有消息被裁剪
+	query = db.DB.
+		Where("uid = ? AND NOT EXISTS (SELECT 1 FROM `im_offline_message` o WHERE o.`uid` = ? AND o.`id` > ?)", uid, uid, cursor).
+		Delete(&OfflineMissed{})
+	return common.JustError(query)
+}
+
+func (chatMsgDaoImpl) TrimOfflineMessage(uid int64, max int64) (int64, error) {
+	var trimmed int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// 删除比最新的第 max+1 条更早的消息, 单条语句完成, 并发裁剪时不会重复删除或统计
+		query := tx.
+			Where("uid = ? AND id <= (SELECT t.id FROM (SELECT `id` FROM `im_offline_message` WHERE `uid` = ? ORDER BY `id` DESC LIMIT 1 OFFSET ?) t)", uid, uid, max).
+			Delete(&OfflineMessage{})
+		if err := common.JustError(query); err != nil {
+			return err
+		}
+		trimmed = query.RowsAffected
+		if trimmed == 0 {
+			return nil
+		}
+		missed := &OfflineMissed{
+			UID:      uid,
+			Count:    trimmed,
+			UpdateAt: time.Now().Unix(),
+		}
+		// 记录不存在时创建, 否则累加
+		query = tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":     gorm.Expr("`count` + VALUES(`count`)"),
+				"update_at": gorm.Expr("VALUES(`update_at`)"),
+			}),
+		}).Create(missed)
+		return common.JustError(query)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return trimmed, nil
+}
+
+func (chatMsgDaoImpl) GetOfflineMissed(uid int64) (int64, error) {
+	missed := &OfflineMissed{}
+	query := db.DB.Model(missed).Where("uid = ?", uid).Find(missed)
+	if err := common.JustError(query); err != nil {
+		return 0, err
+	}
+	return missed.Count, nil
+}
+
+func (chatMsgDaoImpl) DeleteExpiredOfflineMessage(before int64, limit int) (int64, error) {
+	var id []int64
+	query := db.DB.Model(&OfflineMessage{}).Where("create_at < ?", before).Limit(limit).Pluck("id", &id)
+	if err := common.JustError(query); err != nil {
+		return 0, err
+	}
+	if len(id) == 0 {
+		return 0, nil
+	}
+	query = db.DB.Where("id IN (?)", id).Delete(&OfflineMessage{})
+	if err := common.JustError(query); err != nil {
+		return 0, err
+	}
+	return query.RowsAffected, nil
+}
@@ -32,7 +32,7 @@ func TestChatMsgDao_GetRecentChatMessages(t *testing.T) {
 }
 
 func TestChatMsgDao_GetOfflineMessage(t *testing.T) {
-	m, err := GetOfflineMessage(1)
+	m, err := GetOfflineMessage(1, 0, 0, 20)
 	if err != nil {
 		t.Error(err)
 	}
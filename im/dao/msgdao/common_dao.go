@@ -1,7 +1,7 @@
 package msgdao
 
 import (
-	"github.com/glide-im/glideim/pkg/db"
+	"github.com/glide-im/glideim/im/dao/mid"
 )
 
 var Comm CommonDao = commonDao{}
@@ -10,10 +10,5 @@ type commonDao struct {
 }
 
 func (commonDao) GetMessageID() (int64, error) {
-	// TODO 2021-12-17 16:57:04
-	result, err := db.Redis.Incr("im:msg:id:incr").Result()
-	if err != nil {
-		return 0, err
-	}
-	return result, nil
+	return mid.GetMid()
 }
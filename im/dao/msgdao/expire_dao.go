@@ -0,0 +1,78 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+)
+
+var ExpireDaoImpl ExpireDao = expireDaoImpl{}
+
+type expireDaoImpl struct {
+}
+
+func (expireDaoImpl) GetExpiredChatMessages(before int64, limit int) ([]*ChatMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*ChatMessage{}
+	query := db.DB.Model(&ChatMessage{}).
+		Where("`expire_at` > 0 AND `expire_at` <= ?", before).
+		Order("`expire_at` ASC").
+		Limit(limit).
+		Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (expireDaoImpl) GetExpiredGroupMessages(before int64, limit int) ([]*GroupMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*GroupMessage{}
+	query := db.DB.Model(&GroupMessage{}).
+		Where("`expire_at` > 0 AND `expire_at` <= ?", before).
+		Order("`expire_at` ASC").
+		Limit(limit).
+		Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (expireDaoImpl) DeleteChatMessages(mid ...int64) error {
+	if len(mid) == 0 {
+		return nil
+	}
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := common.JustError(tx.Where("m_id IN (?)", mid).Delete(&OfflineMessage{})); err != nil {
+			return err
+		}
+		if err := deleteMessageExtras(tx, mid); err != nil {
+			return err
+		}
+		return common.JustError(tx.Where("m_id IN (?)", mid).Delete(&ChatMessage{}))
+	})
+}
+
+func (expireDaoImpl) DeleteGroupMessages(mid ...int64) error {
+	if len(mid) == 0 {
+		return nil
+	}
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteMessageExtras(tx, mid); err != nil {
+			return err
+		}
+		return common.JustError(tx.Where("m_id IN (?)", mid).Delete(&GroupMessage{}))
+	})
+}
+
+// deleteMessageExtras 删除消息的编辑记录, 表情回应和全文索引
+func deleteMessageExtras(tx *gorm.DB, mid []int64) error {
+	if err := common.JustError(tx.Where("m_id IN (?)", mid).Delete(&MessageRevision{})); err != nil {
+		return err
+	}
+	if err := common.JustError(tx.Where("m_id IN (?)", mid).Delete(&MessageToken{})); err != nil {
+		return err
+	}
+	return common.JustError(tx.Where("m_id IN (?)", mid).Delete(&MessageReaction{}))
+}
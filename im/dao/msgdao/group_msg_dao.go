@@ -3,7 +3,9 @@ package msgdao
 import (
 	"github.com/glide-im/glideim/im/dao/common"
 	"github.com/glide-im/glideim/pkg/db"
-	"strconv"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
 	"time"
 )
 
@@ -21,32 +23,76 @@ func (groupMsgDaoImpl) GetGroupMsgSeq(gid int64) (int64, error) {
 	return m.Seq, nil
 }
 
-func (groupMsgDaoImpl) UpdateGroupMsgSeq(gid int64, seq int64) error {
-	query := db.DB.Model(&GroupMsgSeq{}).Where("gid = ?", gid).Update("seq", seq)
-	if err := common.ResolveError(query); err != nil {
-		return err
+func (groupMsgDaoImpl) InitGroupMsgSeq(gid int64, step int64) (int64, error) {
+	m := &GroupMsgSeq{}
+	query := db.DB.Model(m).Where("gid = ?", gid).Find(m)
+	err := common.MustFind(query)
+	if err == nil {
+		return m.Seq, nil
+	}
+	if err != common.ErrNoRecordFound {
+		return 0, err
+	}
+	// 没有 seq 记录的群从已保存的消息中恢复, 避免与已有消息的 seq 重复
+	var maxSeq int64
+	err = db.DB.Model(&GroupMessage{}).
+		Select("COALESCE(MAX(`seq`), 0)").
+		Where("`to` = ?", gid).
+		Row().
+		Scan(&maxSeq)
+	if err != nil {
+		return 0, err
+	}
+	state := &GroupMessageState{}
+	query = db.DB.Model(state).Where("gid = ?", gid).Find(state)
+	if err = common.JustError(query); err != nil {
+		return 0, err
+	}
+	if state.LastSeq > maxSeq {
+		maxSeq = state.LastSeq
 	}
-	return nil
-}
-
-func (groupMsgDaoImpl) CreateGroupMsgSeq(gid int64, step int64) error {
 	model := &GroupMsgSeq{
 		Gid:  gid,
-		Seq:  0,
+		Seq:  maxSeq,
 		Step: step,
 	}
-	query := db.DB.Model(model).Create(model)
-	if err := common.ResolveError(query); err != nil {
-		return err
+	// 其他节点可能同时创建, 以已存在的记录为准
+	query = db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+	if err = common.JustError(query); err != nil {
+		return 0, err
 	}
-	return nil
+	return GroupMsgDaoImpl.GetGroupMsgSeq(gid)
+}
+
+func (groupMsgDaoImpl) LeaseGroupMsgSeq(gid int64, defaultStep int64) (int64, int64, error) {
+	m := &GroupMsgSeq{}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// 旧数据中步长可能为 0 或 NULL, 此时使用默认步长并修正记录, 否则每次租用不会前进, 分配出重复的 seq.
+		// MySQL 按顺序执行赋值, seq 使用修正前的 step
+		query := tx.Model(&GroupMsgSeq{}).
+			Where("gid = ?", gid).
+			Updates(map[string]interface{}{
+				"seq":  gorm.Expr("COALESCE(`seq`, 0) + IF(COALESCE(`step`, 0) > 0, `step`, ?)", defaultStep),
+				"step": gorm.Expr("IF(COALESCE(`step`, 0) > 0, `step`, ?)", defaultStep),
+			})
+		if err := common.MustUpdate(query); err != nil {
+			return err
+		}
+		query = tx.Model(m).Where("gid = ?", gid).Find(m)
+		return common.ResolveError(query)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.Seq, m.Step, nil
 }
 
-func (groupMsgDaoImpl) GetLatestGroupMessage(gid int64, pageSize int) ([]*GroupMessage, error) {
+func (groupMsgDaoImpl) GetLatestGroupMessage(uid int64, gid int64, pageSize int) ([]*GroupMessage, error) {
 	//goland:noinspection GoPreferNilSlice
 	ms := []*GroupMessage{}
 	query := db.DB.Model(&GroupMessage{}).
 		Where("`to` = ?", gid).
+		Scopes(visibleGroupMessages(uid)).
 		Order("`send_at` DESC").
 		Limit(pageSize).
 		Find(&ms)
@@ -56,12 +102,13 @@ func (groupMsgDaoImpl) GetLatestGroupMessage(gid int64, pageSize int) ([]*GroupM
 	return ms, nil
 }
 
-func (groupMsgDaoImpl) GetGroupMessage(gid int64, beforeSeq int64, pageSize int) ([]*GroupMessage, error) {
+func (groupMsgDaoImpl) GetGroupMessage(uid int64, gid int64, beforeSeq int64, pageSize int) ([]*GroupMessage, error) {
 
 	//goland:noinspection GoPreferNilSlice
 	ms := []*GroupMessage{}
 	query := db.DB.Model(&GroupMessage{}).
 		Where("`to` = ? AND `seq` < ?", gid, beforeSeq).
+		Scopes(visibleGroupMessages(uid)).
 		Order("`send_at` DESC").
 		Limit(pageSize).
 		Find(&ms)
@@ -83,8 +130,24 @@ func (groupMsgDaoImpl) GetMessage(mid int64) (*GroupMessage, error) {
 func (groupMsgDaoImpl) GetMessages(mid ...int64) ([]*GroupMessage, error) {
 	//goland:noinspection GoPreferNilSlice
 	gm := []*GroupMessage{}
-	query := db.DB.Model(gm).Where("m_id IN (?)", mid).Find(gm)
-	if err := common.ResolveError(query); err != nil {
+	query := db.DB.Model(&GroupMessage{}).Where("m_id IN (?)", mid).Find(&gm)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return gm, nil
+}
+
+func (groupMsgDaoImpl) GetVisibleMessages(uid int64, mid ...int64) ([]*GroupMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	gm := []*GroupMessage{}
+	if len(mid) == 0 {
+		return gm, nil
+	}
+	query := db.DB.Model(&GroupMessage{}).
+		Where("`m_id` IN (?)", mid).
+		Scopes(visibleGroupMessages(uid)).
+		Find(&gm)
+	if err := common.JustError(query); err != nil {
 		return nil, err
 	}
 	return gm, nil
@@ -107,7 +170,23 @@ func (groupMsgDaoImpl) UpdateGroupMessageRecall(gid int64, mid int64, status int
 		RecallBy: by,
 	}
 	updates := db.DB.Updates(&message)
-	return common.MustUpdate(updates)
+	if err := common.MustUpdate(updates); err != nil {
+		return err
+	}
+	if status == ChatMessageStatusRecalled {
+		removeIndex(mid)
+	}
+	return nil
+}
+
+func (groupMsgDaoImpl) EditGroupMessage(gid int64, mid int64, from int64, content string, editAt int64) error {
+	where := "`m_id` = ? AND `to` = ? AND `from` = ? AND `status` = ?"
+	err := editMessage(&GroupMessage{}, where, mid, content, editAt, mid, gid, from, ChatMessageStatusDefault)
+	if err != nil {
+		return err
+	}
+	indexMessage(mid, true, content)
+	return nil
 }
 
 func (groupMsgDaoImpl) AddGroupMessage(message *GroupMessage) error {
@@ -115,13 +194,12 @@ func (groupMsgDaoImpl) AddGroupMessage(message *GroupMessage) error {
 	if err := common.ResolveError(query); err != nil {
 		return err
 	}
+	indexMessage(message.MID, true, message.Content)
 	return nil
 }
 
 func (groupMsgDaoImpl) CreateGroupMemberMsgState(gid int64, uid int64) error {
-	mbId := strconv.FormatInt(gid, 10) + strconv.FormatInt(uid, 10)
 	model := &GroupMemberMsgState{
-		MbID:       mbId,
 		Gid:        gid,
 		UID:        uid,
 		LastAckMID: 0,
@@ -183,12 +261,19 @@ func (groupMsgDaoImpl) GetGroupsMessageState(gid ...int64) ([]*GroupMessageState
 }
 
 func (groupMsgDaoImpl) UpdateGroupMemberMsgState(gid int64, uid int64, ackMid int64, ackSeq int64) error {
-	mbId := strconv.FormatInt(gid, 10) + strconv.FormatInt(uid, 10)
-	s := &GroupMemberMsgState{}
-	query := db.DB.Model(s).Where("mb_id = ?", mbId).Updates(GroupMemberMsgState{
+	s := &GroupMemberMsgState{
+		Gid:        gid,
+		UID:        uid,
 		LastAckMID: ackMid,
 		LastAckSeq: ackSeq,
-	})
+	}
+	// 记录不存在时创建, 确认位置只前进不后退
+	query := db.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_ack_m_id": gorm.Expr("IF(VALUES(`last_ack_seq`) > `last_ack_seq`, VALUES(`last_ack_m_id`), `last_ack_m_id`)"),
+			"last_ack_seq":  gorm.Expr("GREATEST(`last_ack_seq`, VALUES(`last_ack_seq`))"),
+		}),
+	}).Create(s)
 	if err := common.ResolveError(query); err != nil {
 		return err
 	}
@@ -197,10 +282,109 @@ func (groupMsgDaoImpl) UpdateGroupMemberMsgState(gid int64, uid int64, ackMid in
 
 func (groupMsgDaoImpl) GetGroupMemberMsgState(gid int64, uid int64) (*GroupMemberMsgState, error) {
 	state := &GroupMemberMsgState{}
-	mbId := strconv.FormatInt(gid, 10) + strconv.FormatInt(uid, 10)
-	query := db.DB.Model(state).Where("mb_id = ?", mbId).Find(state)
+	query := db.DB.Model(state).Where("gid = ? AND uid = ?", gid, uid).Find(state)
 	if err := common.ResolveError(query); err != nil {
 		return nil, err
 	}
 	return state, nil
 }
+
+func (groupMsgDaoImpl) GetGroupMemberMsgStates(gid int64, uid ...int64) ([]*GroupMemberMsgState, error) {
+	//goland:noinspection GoPreferNilSlice
+	states := []*GroupMemberMsgState{}
+	if len(uid) == 0 {
+		return states, nil
+	}
+	query := db.DB.Model(&GroupMemberMsgState{}).Where("gid = ? AND uid IN (?)", gid, uid).Find(&states)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (groupMsgDaoImpl) GetUserGroupMsgStates(uid int64, gid ...int64) ([]*GroupMemberMsgState, error) {
+	//goland:noinspection GoPreferNilSlice
+	states := []*GroupMemberMsgState{}
+	if len(gid) == 0 {
+		return states, nil
+	}
+	query := db.DB.Model(&GroupMemberMsgState{}).Where("uid = ? AND gid IN (?)", uid, gid).Find(&states)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (groupMsgDaoImpl) GetGroupMessageSeqSpan(gid int64, seqStart, seqEnd int64) ([]*GroupMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*GroupMessage{}
+	query := db.DB.Model(&GroupMessage{}).
+		Where("`to` = ? AND `seq` >= ? AND `seq` <= ?", gid, seqStart, seqEnd).
+		Order("`seq` ASC").
+		Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+type ackSeqCount struct {
+	LastAckSeq int64
+	Count      int64
+}
+
+func (groupMsgDaoImpl) GetGroupMessageReadCount(gid int64, seq ...int64) (map[int64]int64, error) {
+	result := map[int64]int64{}
+	if len(seq) == 0 {
+		return result, nil
+	}
+	seqs := make([]int64, len(seq))
+	copy(seqs, seq)
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] > seqs[j]
+	})
+
+	// 按确认位置分组计数, 只需一次查询即可得到所有 seq 的已读人数
+	var counts []*ackSeqCount
+	query := db.DB.Model(&GroupMemberMsgState{}).
+		Select("`last_ack_seq`, COUNT(*) AS `count`").
+		Where("gid = ? AND `last_ack_seq` >= ?", gid, seqs[len(seqs)-1]).
+		Scopes(currentMembers(gid)).
+		Group("`last_ack_seq`").
+		Order("`last_ack_seq` DESC").
+		Scan(&counts)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+
+	var total int64
+	i := 0
+	for _, s := range seqs {
+		for i < len(counts) && counts[i].LastAckSeq >= s {
+			total += counts[i].Count
+			i++
+		}
+		result[s] = total
+	}
+	return result, nil
+}
+
+func (groupMsgDaoImpl) GetGroupMessageReadMembers(gid int64, seq int64) ([]int64, error) {
+	//goland:noinspection GoPreferNilSlice
+	uid := []int64{}
+	query := db.DB.Model(&GroupMemberMsgState{}).
+		Where("gid = ? AND `last_ack_seq` >= ?", gid, seq).
+		Scopes(currentMembers(gid)).
+		Pluck("uid", &uid)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return uid, nil
+}
+
+// currentMembers 只统计当前群成员的确认记录, 已退出的成员的记录不删除
+func currentMembers(gid int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("uid IN (SELECT `uid` FROM `im_group_member_model` WHERE `gid` = ?)", gid)
+	}
+}
@@ -12,11 +12,19 @@ func init() {
 	db.Init()
 }
 
-func TestGroupMsgDao_CreateGroupMsgSeq(t *testing.T) {
-	err := CreateGroupMsgSeq(1, 100)
+func TestGroupMsgDao_InitGroupMsgSeq(t *testing.T) {
+	seq, err := InitGroupMsgSeq(1, 100)
 	if err != nil {
 		t.Error(err)
 	}
+	max, step, err := LeaseGroupMsgSeq(1, 100)
+	if err != nil {
+		t.Error(err)
+	}
+	if step <= 0 || max-step < seq {
+		t.Errorf("leased segment (%d, %d] overlaps allocated seq %d", max-step, max, seq)
+	}
+	t.Log(seq, max, step)
 }
 
 func TestGroupMsgDao_GetGroupMsgSeq(t *testing.T) {
@@ -94,3 +102,27 @@ func TestGroupMsgDao_UpdateGroupMemberMsgState(t *testing.T) {
 		t.Log(err)
 	}
 }
+
+func TestGroupMsgDaoImpl_GetGroupMessageReadCount(t *testing.T) {
+	counts, err := GroupMsgDaoImpl.GetGroupMessageReadCount(1, 1, 2, 3)
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(counts)
+}
+
+func TestGroupMsgDaoImpl_GetGroupMessageReadMembers(t *testing.T) {
+	uid, err := GroupMsgDaoImpl.GetGroupMessageReadMembers(1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(uid)
+}
+
+func TestGroupMsgDaoImpl_GetUserGroupMsgStates(t *testing.T) {
+	states, err := GroupMsgDaoImpl.GetUserGroupMsgStates(1, 1, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(states)
+}
@@ -0,0 +1,136 @@
+package msgdao
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+var InboxDaoImpl InboxDao = inboxDaoImpl{}
+
+// AddInboxEvent 将事件数据编码为 json 后写入用户收件箱
+func AddInboxEvent(action string, mid int64, gid int64, data interface{}, uid ...int64) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	e := &InboxEvent{
+		Action: action,
+		MID:    mid,
+		Gid:    gid,
+		Data:   string(d),
+	}
+	return InboxDaoImpl.AddInboxEvent(e, uid...)
+}
+
+type inboxDaoImpl struct {
+}
+
+// AddInboxEvent 在同一事务中分配 Seq 并写入事件, 用户的 Seq 记录在事务提交前保持锁定,
+// 后分配的 Seq 不会先于之前的 Seq 可见, 同步时按 Seq 读取不会跳过未提交的事件
+func (inboxDaoImpl) AddInboxEvent(e *InboxEvent, uid ...int64) error {
+	uid = uniqueSortedUid(uid)
+	if len(uid) == 0 {
+		return nil
+	}
+	if e.CreateAt == 0 {
+		e.CreateAt = time.Now().Unix()
+	}
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextInboxSeq(tx, uid)
+		if err != nil {
+			return err
+		}
+		var es []*InboxEvent
+		for _, u := range uid {
+			ue := *e
+			ue.ID = 0
+			ue.UID = u
+			ue.Seq = seq[u]
+			es = append(es, &ue)
+		}
+		return common.ResolveError(tx.Create(&es))
+	})
+}
+
+func (inboxDaoImpl) GetInboxEvents(uid int64, afterSeq int64, limit int) ([]*InboxEvent, error) {
+	//goland:noinspection GoPreferNilSlice
+	es := []*InboxEvent{}
+	query := db.DB.Model(&InboxEvent{}).
+		Where("`uid` = ? AND `seq` > ?", uid, afterSeq).
+		Order("`seq` ASC").
+		Limit(limit).
+		Find(&es)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return es, nil
+}
+
+// nextInboxSeq 在事务中递增每个用户的收件箱 Seq, 按 uid 顺序加锁避免死锁.
+// Seq 记录不存在 (首次写入或从旧数据迁移) 时从已保存事件的最大 Seq 继续, 新记录在事务中已锁定, 不会被并发覆盖
+func nextInboxSeq(tx *gorm.DB, uid []int64) (map[int64]int64, error) {
+	var rows []*InboxSeq
+	for _, u := range uid {
+		rows = append(rows, &InboxSeq{UID: u, Seq: 1})
+	}
+	query := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"seq": gorm.Expr("`seq` + 1"),
+		}),
+	}).Create(&rows)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoPreferNilSlice
+	rs := []*InboxSeq{}
+	query = tx.Model(&InboxSeq{}).Where("`uid` IN ?", uid).Find(&rs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	seq := map[int64]int64{}
+	for _, r := range rs {
+		seq[r.UID] = r.Seq
+		if r.Seq != 1 {
+			continue
+		}
+		var max int64
+		row := tx.Model(&InboxEvent{}).
+			Select("IFNULL(MAX(`seq`), 0)").
+			Where("`uid` = ?", r.UID).
+			Row()
+		if err := row.Scan(&max); err != nil {
+			return nil, err
+		}
+		if max == 0 {
+			continue
+		}
+		query = tx.Model(&InboxSeq{}).Where("`uid` = ?", r.UID).Update("seq", max+1)
+		if err := common.JustError(query); err != nil {
+			return nil, err
+		}
+		seq[r.UID] = max + 1
+	}
+	if len(seq) != len(uid) {
+		return nil, errors.New("allocate inbox seq failed")
+	}
+	return seq, nil
+}
+
+func uniqueSortedUid(uid []int64) []int64 {
+	m := map[int64]bool{}
+	var ret []int64
+	for _, u := range uid {
+		if !m[u] {
+			m[u] = true
+			ret = append(ret, u)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
@@ -1,6 +1,7 @@
 package msgdao
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -17,6 +18,10 @@ func (c *chatMsgMock) GetChatMessage(mid ...int64) ([]*ChatMessage, error) {
 	panic("implement me")
 }
 
+func (c *chatMsgMock) GetVisibleChatMessages(uid int64, mid ...int64) ([]*ChatMessage, error) {
+	panic("implement me")
+}
+
 func (c *chatMsgMock) GetChatMessagesBySession(uid1, uid2 int64, beforeMid int64, pageSize int) ([]*ChatMessage, error) {
 	panic("implement me")
 }
@@ -39,6 +44,11 @@ func (c *chatMsgMock) UpdateChatMessageStatus(mid int64, from, to int64, status
 	return nil
 }
 
+func (c *chatMsgMock) EditChatMessage(mid int64, from int64, content string, editAt int64) error {
+	time.Sleep(c.s)
+	return nil
+}
+
 func (c *chatMsgMock) GetChatMessageMidAfter(form, to int64, midAfter int64) ([]*ChatMessage, error) {
 	panic("implement me")
 }
@@ -47,12 +57,16 @@ func (c *chatMsgMock) GetChatMessageMidSpan(from, to int64, midStart, midEnd int
 	panic("implement me")
 }
 
+func (c *chatMsgMock) HasChatMessageFrom(from int64, to int64) (bool, error) {
+	panic("implement me")
+}
+
 func (c *chatMsgMock) AddOfflineMessage(uid int64, mid int64) error {
 	time.Sleep(c.s)
 	return nil
 }
 
-func (c *chatMsgMock) GetOfflineMessage(uid int64) ([]*OfflineMessage, error) {
+func (c *chatMsgMock) GetOfflineMessage(uid int64, cursor int64, since int64, limit int) ([]*OfflineMessage, error) {
 	panic("implement me")
 }
 
@@ -60,6 +74,23 @@ func (c *chatMsgMock) DelOfflineMessage(uid int64, mid []int64) error {
 	panic("implement me")
 }
 
+func (c *chatMsgMock) AckOfflineMessage(uid int64, cursor int64) error {
+	panic("implement me")
+}
+
+func (c *chatMsgMock) TrimOfflineMessage(uid int64, max int64) (int64, error) {
+	time.Sleep(c.s)
+	return 0, nil
+}
+
+func (c *chatMsgMock) GetOfflineMissed(uid int64) (int64, error) {
+	panic("implement me")
+}
+
+func (c *chatMsgMock) DeleteExpiredOfflineMessage(before int64, limit int) (int64, error) {
+	panic("implement me")
+}
+
 type commMock struct {
 	id int64
 }
@@ -71,3 +102,35 @@ func MockCommDao() {
 func (c *commMock) GetMessageID() (int64, error) {
 	return atomic.AddInt64(&c.id, 1), nil
 }
+
+// groupMsgSeqMock 在内存中分配群消息 seq 号段, 其他方法使用原实现
+type groupMsgSeqMock struct {
+	GroupMsgDao
+	mu   sync.Mutex
+	seq  map[int64]int64
+	step int64
+}
+
+// MockGroupMsgSeq 群消息 seq 号段在内存中分配, step 为记录中的步长, 不大于 0 时与数据库一样使用默认步长
+func MockGroupMsgSeq(step int64) {
+	i := instance.(impl)
+	i.GroupMsgDao = &groupMsgSeqMock{GroupMsgDao: i.GroupMsgDao, seq: map[int64]int64{}, step: step}
+	instance = i
+}
+
+func (g *groupMsgSeqMock) InitGroupMsgSeq(gid int64, step int64) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.seq[gid], nil
+}
+
+func (g *groupMsgSeqMock) LeaseGroupMsgSeq(gid int64, defaultStep int64) (int64, int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	step := g.step
+	if step <= 0 {
+		step = defaultStep
+	}
+	g.seq[gid] += step
+	return g.seq[gid], step, nil
+}
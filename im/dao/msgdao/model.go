@@ -22,6 +22,10 @@ type ChatMessage struct {
 	Content string
 	// Status 消息状态
 	Status int
+	// EditAt 最后一次编辑时间, 0 表示未编辑过
+	EditAt int64
+	// ExpireAt 消息过期时间, 过期后删除, 0 表示不过期
+	ExpireAt int64
 }
 
 // Session 会话, 记录会话的情况
@@ -32,6 +36,11 @@ type Session struct {
 	Uid2        int64
 	LgUidUnread int64
 	SmUidUnread int64
+	// LgUidRead, SmUidRead 双方已读到的消息 ID
+	LgUidRead int64
+	SmUidRead int64
+	// TTL 会话的消息存活时间, 单位秒, 0 表示消息不过期
+	TTL int64
 	// LastMID 最后一条消息的ID
 	LastMID int64
 	// UpdateAt 最后一条消息的时间
@@ -39,11 +48,19 @@ type Session struct {
 	CreateAt int64
 }
 
-// OfflineMessage 用户不在线, 离线消息
+// OfflineMessage 用户不在线, 离线消息, ID 同时作为分页和确认的游标
 type OfflineMessage struct {
-	ID  int64 `gorm:"primaryKey"`
-	MID int64
-	UID int64
+	ID       int64 `gorm:"primaryKey"`
+	MID      int64
+	UID      int64
+	CreateAt int64
+}
+
+// OfflineMissed 离线消息超过上限时裁剪掉的最早消息数量, 确认到最后一条离线消息后清零
+type OfflineMissed struct {
+	UID      int64 `gorm:"primaryKey"`
+	Count    int64
+	UpdateAt int64
 }
 
 // GroupMessage 全量群消息
@@ -60,14 +77,51 @@ type GroupMessage struct {
 	Content  string
 	Status   int
 	RecallBy int64
+	// EditAt 最后一次编辑时间, 0 表示未编辑过
+	EditAt int64
+	// ExpireAt 消息过期时间, 过期后删除, 0 表示不过期
+	ExpireAt int64
+	// CreateAt 服务端保存消息的时间, 编辑时限以此计算
+	CreateAt int64
+}
+
+// MessageRevision 消息编辑记录, 保存每次编辑前的消息内容
+type MessageRevision struct {
+	ID int64 `gorm:"primaryKey"`
+	// MID 被编辑的消息 ID
+	MID int64
+	// Content 编辑前的内容
+	Content string
+	// EditAt 本次编辑的时间
+	EditAt int64
+}
+
+// ScheduledMessage 定时消息, 到达发送时间后由服务端代替发送者发出
+type ScheduledMessage struct {
+	ID int64 `gorm:"primaryKey"`
+	// From 发送者 ID
+	From int64
+	// To 单聊为接收者 ID, 群聊为群 ID
+	To int64
+	// Group 是否为群消息
+	Group bool
+	Type  int32
+	// Content 消息内容
+	Content string
+	// SendAt 计划发送时间
+	SendAt int64
+	// Status 定时消息状态
+	Status int
+	// MID 发送后的消息 ID
+	MID      int64
+	CreateAt int64
 }
 
 // GroupMemberMsgState 群成员确认收到消息记录, 用于计算离线消息的同步量
 type GroupMemberMsgState struct {
-	// MbID 群成员ID, Gid+UID 拼接成
-	MbID string `gorm:"primaryKey"`
-	Gid  int64
-	UID  int64
+	// Gid, UID 组成主键
+	Gid int64 `gorm:"primaryKey"`
+	UID int64 `gorm:"primaryKey"`
 	// LastAckMID 最后一次确认收到的消息 id
 	LastAckMID int64
 	// LastAckSeq 最后一次确认收到的消息 seq
@@ -93,3 +147,98 @@ type GroupMsgSeq struct {
 	// Step 增长步长
 	Step int64
 }
+
+// MessageReaction 消息表情回应, 每个用户对同一消息的同一表情只记录一次
+type MessageReaction struct {
+	ID int64 `gorm:"primaryKey"`
+	// MID 被回应的消息 ID
+	MID int64
+	// UID 回应者 ID
+	UID   int64
+	Emoji string
+	// CreateAt 回应时间
+	CreateAt int64
+}
+
+// InboxEvent 用户收件箱事件, 用户需要看到的单聊消息, 群消息, 通知, 撤回, 已读回执等事件按 Seq 顺序写入收件箱,
+// 设备按 Seq 同步
+type InboxEvent struct {
+	ID int64 `gorm:"primaryKey"`
+	// UID 收件箱所属用户
+	UID int64
+	// Seq 用户收件箱内单调递增的序号
+	Seq int64
+	// Action 事件对应的消息动作
+	Action string
+	// MID 事件相关的消息 ID
+	MID int64
+	// Gid 群事件的群 ID, 单聊事件为 0
+	Gid int64
+	// Data 事件数据, json
+	Data     string
+	CreateAt int64
+}
+
+// InboxSeq 用户收件箱最后分配的 Seq, 与事件在同一事务中递增, 保证 Seq 按提交顺序可见
+type InboxSeq struct {
+	UID int64 `gorm:"primaryKey"`
+	Seq int64
+}
+
+// InboxGroupPointer 群消息在成员收件箱中的指针, 设备根据群 seq 拉取群消息
+type InboxGroupPointer struct {
+	Gid int64
+	Mid int64
+	Seq int64
+}
+
+// MessageHidden 用户删除的消息, 仅对该用户不可见
+type MessageHidden struct {
+	ID  int64 `gorm:"primaryKey"`
+	UID int64
+	// MID 被删除的消息 ID
+	MID      int64
+	CreateAt int64
+}
+
+// MessageClear 用户清空单聊或群聊天记录的位置, 位置及之前的消息对该用户不可见
+type MessageClear struct {
+	ID  int64 `gorm:"primaryKey"`
+	UID int64
+	// Target 单聊为对方 ID, 群聊为群 ID
+	Target int64
+	Group  bool
+	// Position 单聊为消息创建时间, 群聊为群消息 seq
+	Position int64
+	UpdateAt int64
+}
+
+// MessageToken 消息全文索引, 消息内容的每个词一条记录
+type MessageToken struct {
+	ID    int64 `gorm:"primaryKey"`
+	Token string
+	MID   int64
+	Group bool
+}
+
+const (
+	ModerationReviewPending  = 0
+	ModerationReviewApproved = 1
+	ModerationReviewRejected = 2
+)
+
+// ModerationReview 内容审核标记待人工审核的消息, 消息已正常发送
+type ModerationReview struct {
+	ID    int64 `gorm:"primaryKey"`
+	MID   int64
+	Group bool
+	From  int64
+	// To 单聊为接收者 ID, 群聊为群 ID
+	To      int64
+	Content string
+	// Hits 命中的词或标签, 逗号分隔
+	Hits     string
+	Reason   string
+	Status   int
+	CreateAt int64
+}
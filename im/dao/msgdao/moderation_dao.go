@@ -0,0 +1,37 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+)
+
+var ModerationDaoImpl ModerationDao = moderationDaoImpl{}
+
+type moderationDaoImpl struct {
+}
+
+func (moderationDaoImpl) AddReview(r *ModerationReview) error {
+	query := db.DB.Create(r)
+	return common.ResolveError(query)
+}
+
+func (moderationDaoImpl) GetPendingReviews(afterId int64, limit int) ([]*ModerationReview, error) {
+	//goland:noinspection GoPreferNilSlice
+	rs := []*ModerationReview{}
+	query := db.DB.Model(&ModerationReview{}).
+		Where("`id` > ? AND `status` = ?", afterId, ModerationReviewPending).
+		Order("`id` ASC").
+		Limit(limit).
+		Find(&rs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (moderationDaoImpl) UpdateReviewStatus(id int64, status int) error {
+	query := db.DB.Model(&ModerationReview{}).
+		Where("`id` = ? AND `status` = ?", id, ModerationReviewPending).
+		Update("status", status)
+	return common.MustUpdate(query)
+}
@@ -1,5 +1,7 @@
 package msgdao
 
+import "time"
+
 var instance MsgDao
 
 func init() {
@@ -13,16 +15,24 @@ func init() {
 
 type GroupMsgDao interface {
 	GetGroupMsgSeq(gid int64) (int64, error)
-	UpdateGroupMsgSeq(gid int64, seq int64) error
-	CreateGroupMsgSeq(gid int64, step int64) error
+	// InitGroupMsgSeq 获取群已分配的最大 seq, 记录不存在时以已保存消息的最大 seq 创建记录
+	InitGroupMsgSeq(gid int64, step int64) (int64, error)
+	// LeaseGroupMsgSeq 按记录中的步长租用下一个 seq 号段, 步长无效时使用 defaultStep, 返回租用后的最大 seq 和步长, 租到的号段为 (seq-step, seq]
+	LeaseGroupMsgSeq(gid int64, defaultStep int64) (int64, int64, error)
 
 	GetMessage(mid int64) (*GroupMessage, error)
 	GetMessages(mid ...int64) ([]*GroupMessage, error)
+	// GetVisibleMessages 获取 mid 中对 uid 可见的群消息, 排除用户删除的和清空位置及之前的消息
+	GetVisibleMessages(uid int64, mid ...int64) ([]*GroupMessage, error)
 
-	GetLatestGroupMessage(gid int64, pageSize int) ([]*GroupMessage, error)
-	GetGroupMessage(gid int64, beforeSeq int64, pageSize int) ([]*GroupMessage, error)
+	// GetLatestGroupMessage 获取群中对 uid 可见的最新消息
+	GetLatestGroupMessage(uid int64, gid int64, pageSize int) ([]*GroupMessage, error)
+	// GetGroupMessage 获取群中 seq 在 beforeSeq 之前且对 uid 可见的消息
+	GetGroupMessage(uid int64, gid int64, beforeSeq int64, pageSize int) ([]*GroupMessage, error)
 	GetGroupMessageSeqAfter(gid int64, seqAfter int64) ([]*GroupMessage, error)
 	UpdateGroupMessageRecall(gid int64, mid int64, status int, by int64) error
+	// EditGroupMessage 编辑群消息内容, 编辑前的内容保存为修订记录
+	EditGroupMessage(gid int64, mid int64, from int64, content string, editAt int64) error
 
 	AddGroupMessage(message *GroupMessage) error
 	UpdateGroupMessageState(gid int64, lastMID int64, lastMsgAt int64, lastMsgSeq int64) error
@@ -33,30 +43,160 @@ type GroupMsgDao interface {
 	CreateGroupMemberMsgState(gid int64, uid int64) error
 	UpdateGroupMemberMsgState(gid int64, uid int64, lastAck int64, lastAckSeq int64) error
 	GetGroupMemberMsgState(gid int64, uid int64) (*GroupMemberMsgState, error)
+	GetGroupMemberMsgStates(gid int64, uid ...int64) ([]*GroupMemberMsgState, error)
+	// GetUserGroupMsgStates 获取用户在多个群中的消息确认位置
+	GetUserGroupMsgStates(uid int64, gid ...int64) ([]*GroupMemberMsgState, error)
+
+	// GetGroupMessageSeqSpan 获取 seq 在 [seqStart, seqEnd] 区间内的群消息, 按 seq 升序
+	GetGroupMessageSeqSpan(gid int64, seqStart, seqEnd int64) ([]*GroupMessage, error)
+	// GetGroupMessageReadCount 根据群成员的确认位置计算每个 seq 的已读人数, 返回 seq 到人数的映射
+	GetGroupMessageReadCount(gid int64, seq ...int64) (map[int64]int64, error)
+	// GetGroupMessageReadMembers 获取确认位置不小于 seq 的群成员
+	GetGroupMessageReadMembers(gid int64, seq int64) ([]int64, error)
 }
 
 type ChatMsgDao interface {
 	GetChatMessage(mid ...int64) ([]*ChatMessage, error)
+	// GetVisibleChatMessages 获取 mid 中对 uid 可见的单聊消息, 排除用户删除的和清空位置及之前的消息
+	GetVisibleChatMessages(uid int64, mid ...int64) ([]*ChatMessage, error)
+	// GetChatMessagesBySession 获取 uid1 与 uid2 会话中对 uid1 可见的消息, 下同
 	GetChatMessagesBySession(uid1, uid2 int64, beforeMid int64, pageSize int) ([]*ChatMessage, error)
 	GetRecentChatMessagesBySession(uid1, uid2 int64, pageSize int) ([]*ChatMessage, error)
 	GetRecentChatMessages(uid int64, afterTime int64) ([]*ChatMessage, error)
 	//AddChatMessage return update success(exist message) and error
 	AddChatMessage(message *ChatMessage) (bool, error)
 	UpdateChatMessageStatus(mid int64, from, to int64, status int) error
+	// EditChatMessage 编辑单聊消息内容, 编辑前的内容保存为修订记录
+	EditChatMessage(mid int64, from int64, content string, editAt int64) error
 
 	GetChatMessageMidAfter(form, to int64, midAfter int64) ([]*ChatMessage, error)
+	// HasChatMessageFrom from 是否给 to 发送过消息
+	HasChatMessageFrom(from int64, to int64) (bool, error)
 	GetChatMessageMidSpan(from, to int64, midStart, midEnd int64) ([]*ChatMessage, error)
 
 	AddOfflineMessage(uid int64, mid int64) error
-	GetOfflineMessage(uid int64) ([]*OfflineMessage, error)
+	// GetOfflineMessage 获取 ID 大于 cursor 且不早于 since 的离线消息, 按 ID 升序
+	GetOfflineMessage(uid int64, cursor int64, since int64, limit int) ([]*OfflineMessage, error)
 	DelOfflineMessage(uid int64, mid []int64) error
+	// AckOfflineMessage 删除 ID 不大于 cursor 的离线消息, 确认到最后一条离线消息时清零错过的消息数量
+	AckOfflineMessage(uid int64, cursor int64) error
+	// TrimOfflineMessage 离线消息超过 max 条时删除最早的消息, 返回删除的数量, 删除的数量累加到错过的消息数量
+	TrimOfflineMessage(uid int64, max int64) (int64, error)
+	// GetOfflineMissed 获取因裁剪而错过的离线消息数量
+	GetOfflineMissed(uid int64) (int64, error)
+	// DeleteExpiredOfflineMessage 删除 before 之前保存的离线消息, 每次最多删除 limit 条
+	DeleteExpiredOfflineMessage(before int64, limit int) (int64, error)
+}
+
+type RevisionDao interface {
+	// GetMessageRevisions 获取消息的所有编辑记录, 按编辑时间升序
+	GetMessageRevisions(mid int64) ([]*MessageRevision, error)
+}
+
+type ReactionDao interface {
+	// AddReaction 添加表情回应, 已存在时返回 false
+	AddReaction(mid int64, uid int64, emoji string) (bool, error)
+	// RemoveReaction 移除表情回应
+	RemoveReaction(mid int64, uid int64, emoji string) error
+	// GetReactions 获取多条消息的所有表情回应, 按回应时间升序
+	GetReactions(mid ...int64) ([]*MessageReaction, error)
+}
+
+type ExpireDao interface {
+	// GetExpiredChatMessages 获取过期时间早于 before 的单聊消息
+	GetExpiredChatMessages(before int64, limit int) ([]*ChatMessage, error)
+	// GetExpiredGroupMessages 获取过期时间早于 before 的群消息
+	GetExpiredGroupMessages(before int64, limit int) ([]*GroupMessage, error)
+	// DeleteChatMessages 删除单聊消息及其离线消息, 编辑记录, 表情回应
+	DeleteChatMessages(mid ...int64) error
+	// DeleteGroupMessages 删除群消息及其编辑记录, 表情回应
+	DeleteGroupMessages(mid ...int64) error
+}
+
+type ScheduledDao interface {
+	AddScheduledMessage(m *ScheduledMessage) error
+	// GetScheduledMessages 获取用户所有待发送的定时消息, 按计划发送时间升序
+	GetScheduledMessages(uid int64) ([]*ScheduledMessage, error)
+	// CancelScheduledMessage 取消待发送的定时消息
+	CancelScheduledMessage(id int64, uid int64) error
+	// GetDueScheduledMessages 获取计划发送时间早于 before 的待发送定时消息
+	GetDueScheduledMessages(before int64, limit int) ([]*ScheduledMessage, error)
+	// ClaimScheduledMessage 将待发送的定时消息标记为已发送, 返回是否标记成功, 避免多个实例重复发送
+	ClaimScheduledMessage(id int64, mid int64) (bool, error)
+	// ReleaseScheduledMessage 发送失败时将已标记的定时消息恢复为待发送, 下次检查时重试
+	ReleaseScheduledMessage(id int64, mid int64) error
+	// FailScheduledMessage 将已标记的定时消息标记为发送失败, 不再重试
+	FailScheduledMessage(id int64, mid int64) error
 }
 
 type SessionDao interface {
 	GetSession(uid1 int64, uid2 int64) (*Session, error)
 	CreateSession(uid1 int64, uid2 int64, updateAt int64) (*Session, error)
 	UpdateOrCreateSession(uid1 int64, uid2 int64, sender int64, mid int64, sendAt int64) error
+	CleanUserSessionUnread(uid1, uid2 int64, uid int64) error
+	// UpdateUserSessionRead 更新 uid 在会话中的已读位置并清空其未读数, 返回更新后的已读位置, mid 为 0 时读到会话最后一条消息,
+	// mid 不是该会话的消息时返回 ErrReadMidInvalid, 会话不存在时返回 ErrSessionNotExist
+	UpdateUserSessionRead(uid1, uid2 int64, uid int64, mid int64) (int64, error)
 	GetRecentSession(uid int64, updateBefore int64, pageSize int64) ([]*Session, error)
+	// GetSessionTTL 获取会话的消息存活时间, 单位秒, 0 表示消息不过期
+	GetSessionTTL(uid1 int64, uid2 int64) (int64, error)
+	SetSessionTTL(uid1 int64, uid2 int64, ttl int64) error
+	// GetUnreadCount 获取用户最近 limit 个会话的未读消息总数
+	GetUnreadCount(uid int64, limit int64) (int64, error)
+}
+
+type VisibilityDao interface {
+	// HideMessages 对用户隐藏消息, 即仅为自己删除
+	HideMessages(uid int64, mid ...int64) error
+	// GetHiddenMessages 返回 mid 中对用户隐藏的消息 ID
+	GetHiddenMessages(uid int64, mid ...int64) ([]int64, error)
+	// ClearHistory 清空用户在单聊或群中 position 及之前的聊天记录, 清空位置只前进不后退
+	ClearHistory(uid int64, target int64, group bool, position int64) error
+	// GetClearPositions 获取用户在多个单聊或群中的清空位置, 未清空过的不包含在结果中
+	GetClearPositions(uid int64, group bool, target ...int64) (map[int64]int64, error)
+}
+
+// SearchFilter 消息搜索条件, 关键词以外的条件为 0 时不过滤
+type SearchFilter struct {
+	// Tokens 关键词分词, 消息需要包含所有词
+	Tokens []string
+	// To 单聊为对方 ID, 群聊为群 ID
+	To   int64
+	From int64
+	Type int32
+	// StartAt, EndAt 消息时间范围
+	StartAt int64
+	EndAt   int64
+	// BeforeMid 分页, 返回该消息之前的消息
+	BeforeMid int64
+	Limit     int
+}
+
+type SearchDao interface {
+	// IndexMessage 建立或更新消息的全文索引
+	IndexMessage(mid int64, group bool, content string) error
+	// RemoveIndex 移除消息的全文索引
+	RemoveIndex(mid ...int64) error
+	// SearchChatMessages 搜索用户参与且对用户可见的单聊消息, 按消息 ID 降序
+	SearchChatMessages(uid int64, f *SearchFilter) ([]*ChatMessage, error)
+	// SearchGroupMessages 搜索指定群中对用户可见的消息, 按消息 ID 降序
+	SearchGroupMessages(uid int64, gid []int64, f *SearchFilter) ([]*GroupMessage, error)
+}
+
+type ModerationDao interface {
+	// AddReview 记录待人工审核的消息
+	AddReview(r *ModerationReview) error
+	// GetPendingReviews 获取 ID 大于 afterId 的待审核记录, 按 ID 升序
+	GetPendingReviews(afterId int64, limit int) ([]*ModerationReview, error)
+	// UpdateReviewStatus 更新待审核记录的审核结果
+	UpdateReviewStatus(id int64, status int) error
+}
+
+type InboxDao interface {
+	// AddInboxEvent 将事件写入多个用户的收件箱, 每个用户分配各自的 Seq
+	AddInboxEvent(e *InboxEvent, uid ...int64) error
+	// GetInboxEvents 获取用户收件箱中 Seq 大于 afterSeq 的事件, 按 Seq 升序
+	GetInboxEvents(uid int64, afterSeq int64, limit int) ([]*InboxEvent, error)
 }
 
 type CacheDao interface {
@@ -64,6 +204,15 @@ type CacheDao interface {
 	GetUserMsgSeq(uid int64) (int64, error)
 	// GetIncrUserMsgSeq 返回用户全局消息递增seq, 保证递增, 尽量保持连续, 不保证一定连续
 	GetIncrUserMsgSeq(uid int64) (int64, error)
+
+	// ClaimSend 标记发送者设备的一次发送, 返回 false 表示窗口期内已有相同 cliSeq 的发送
+	ClaimSend(from int64, device int64, cliSeq int64, window time.Duration) (bool, error)
+	// CompleteSend 记录发送成功后的消息 ID
+	CompleteSend(from int64, device int64, cliSeq int64, mid int64, window time.Duration) error
+	// GetSendResult 获取已完成发送的消息 ID, 发送未完成时返回 0
+	GetSendResult(from int64, device int64, cliSeq int64) (int64, error)
+	// ReleaseSend 发送失败时移除标记, 允许客户端重试
+	ReleaseSend(from int64, device int64, cliSeq int64) error
 }
 
 type CommonDao interface {
@@ -105,11 +254,11 @@ func GetMessageID() (int64, error) {
 func GetGroupMsgSeq(gid int64) (int64, error) {
 	return instance.GetGroupMsgSeq(gid)
 }
-func UpdateGroupMsgSeq(gid int64, seq int64) error {
-	return instance.UpdateGroupMsgSeq(gid, seq)
+func InitGroupMsgSeq(gid int64, step int64) (int64, error) {
+	return instance.InitGroupMsgSeq(gid, step)
 }
-func CreateGroupMsgSeq(gid int64, step int64) error {
-	return instance.CreateGroupMsgSeq(gid, step)
+func LeaseGroupMsgSeq(gid int64, defaultStep int64) (int64, int64, error) {
+	return instance.LeaseGroupMsgSeq(gid, defaultStep)
 }
 
 func GetGroupMessage(mid int64) (*GroupMessage, error) {
@@ -151,8 +300,8 @@ func GetChatMessageMidSpan(from, to int64, midStart, midEnd int64) ([]*ChatMessa
 func AddOfflineMessage(uid int64, mid int64) error {
 	return ChatMsgDaoImpl.AddOfflineMessage(uid, mid)
 }
-func GetOfflineMessage(uid int64) ([]*OfflineMessage, error) {
-	return ChatMsgDaoImpl.GetOfflineMessage(uid)
+func GetOfflineMessage(uid int64, cursor int64, since int64, limit int) ([]*OfflineMessage, error) {
+	return ChatMsgDaoImpl.GetOfflineMessage(uid, cursor, since, limit)
 }
 func DelOfflineMessage(uid int64, mid []int64) error {
 	return ChatMsgDaoImpl.DelOfflineMessage(uid, mid)
@@ -0,0 +1,46 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ReactionDaoImpl ReactionDao = reactionDaoImpl{}
+
+type reactionDaoImpl struct {
+}
+
+func (reactionDaoImpl) AddReaction(mid int64, uid int64, emoji string) (bool, error) {
+	r := &MessageReaction{
+		MID:      mid,
+		UID:      uid,
+		Emoji:    emoji,
+		CreateAt: time.Now().Unix(),
+	}
+	// 依赖 (m_id, uid, emoji) 唯一索引, 已存在时不插入
+	query := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(r)
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
+
+func (reactionDaoImpl) RemoveReaction(mid int64, uid int64, emoji string) error {
+	query := db.DB.Where("m_id = ? AND uid = ? AND emoji = ?", mid, uid, emoji).Delete(&MessageReaction{})
+	return common.MustUpdate(query)
+}
+
+func (reactionDaoImpl) GetReactions(mid ...int64) ([]*MessageReaction, error) {
+	//goland:noinspection GoPreferNilSlice
+	rs := []*MessageReaction{}
+	if len(mid) == 0 {
+		return rs, nil
+	}
+	query := db.DB.Model(&MessageReaction{}).Where("m_id IN (?)", mid).Order("`id` ASC").Find(&rs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
@@ -0,0 +1,46 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+)
+
+var RevisionDaoImpl RevisionDao = revisionDaoImpl{}
+
+type revisionDaoImpl struct {
+}
+
+func (revisionDaoImpl) GetMessageRevisions(mid int64) ([]*MessageRevision, error) {
+	//goland:noinspection GoPreferNilSlice
+	rs := []*MessageRevision{}
+	query := db.DB.Model(&MessageRevision{}).Where("m_id = ?", mid).Order("`id` ASC").Find(&rs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// editMessage 在事务中保存消息编辑前的内容, 并将消息更新为新内容, model 为消息所在表的模型
+func editMessage(model interface{}, where string, mid int64, content string, editAt int64, args ...interface{}) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var origin string
+		query := tx.Model(model).Where(where, args...).Select("content").Find(&origin)
+		if err := common.MustFind(query); err != nil {
+			return err
+		}
+		revision := &MessageRevision{
+			MID:     mid,
+			Content: origin,
+			EditAt:  editAt,
+		}
+		if err := common.ResolveError(tx.Create(revision)); err != nil {
+			return err
+		}
+		update := tx.Model(model).Where(where, args...).Updates(map[string]interface{}{
+			"content": content,
+			"edit_at": editAt,
+		})
+		return common.MustUpdate(update)
+	})
+}
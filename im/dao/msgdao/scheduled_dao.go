@@ -0,0 +1,89 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+)
+
+const (
+	ScheduledMessageStatusPending   = 0
+	ScheduledMessageStatusSent      = 1
+	ScheduledMessageStatusCancelled = 2
+	ScheduledMessageStatusFailed    = 3
+)
+
+var ScheduledDaoImpl ScheduledDao = scheduledDaoImpl{}
+
+type scheduledDaoImpl struct {
+}
+
+func (scheduledDaoImpl) AddScheduledMessage(m *ScheduledMessage) error {
+	query := db.DB.Create(m)
+	return common.ResolveError(query)
+}
+
+func (scheduledDaoImpl) GetScheduledMessages(uid int64) ([]*ScheduledMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*ScheduledMessage{}
+	query := db.DB.Model(&ScheduledMessage{}).
+		Where("`from` = ? AND `status` = ?", uid, ScheduledMessageStatusPending).
+		Order("`send_at` ASC").
+		Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (scheduledDaoImpl) CancelScheduledMessage(id int64, uid int64) error {
+	query := db.DB.Model(&ScheduledMessage{}).
+		Where("`id` = ? AND `from` = ? AND `status` = ?", id, uid, ScheduledMessageStatusPending).
+		Update("status", ScheduledMessageStatusCancelled)
+	return common.MustUpdate(query)
+}
+
+func (scheduledDaoImpl) GetDueScheduledMessages(before int64, limit int) ([]*ScheduledMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*ScheduledMessage{}
+	query := db.DB.Model(&ScheduledMessage{}).
+		Where("`status` = ? AND `send_at` <= ?", ScheduledMessageStatusPending, before).
+		Order("`send_at` ASC").
+		Limit(limit).
+		Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (scheduledDaoImpl) ClaimScheduledMessage(id int64, mid int64) (bool, error) {
+	query := db.DB.Model(&ScheduledMessage{}).
+		Where("`id` = ? AND `status` = ?", id, ScheduledMessageStatusPending).
+		Updates(map[string]interface{}{
+			"status": ScheduledMessageStatusSent,
+			"m_id":   mid,
+		})
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
+
+func (scheduledDaoImpl) ReleaseScheduledMessage(id int64, mid int64) error {
+	return updateClaimedScheduledMessage(id, mid, ScheduledMessageStatusPending, 0)
+}
+
+func (scheduledDaoImpl) FailScheduledMessage(id int64, mid int64) error {
+	return updateClaimedScheduledMessage(id, mid, ScheduledMessageStatusFailed, mid)
+}
+
+// updateClaimedScheduledMessage 修改由 mid 标记为已发送的定时消息的状态
+func updateClaimedScheduledMessage(id int64, mid int64, status int, newMid int64) error {
+	query := db.DB.Model(&ScheduledMessage{}).
+		Where("`id` = ? AND `status` = ? AND `m_id` = ?", id, ScheduledMessageStatusSent, mid).
+		Updates(map[string]interface{}{
+			"status": status,
+			"m_id":   newMid,
+		})
+	return common.MustUpdate(query)
+}
@@ -0,0 +1,173 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/glide-im/glideim/pkg/search"
+	"gorm.io/gorm"
+	"sync"
+)
+
+var SearchDaoImpl SearchDao = searchDaoImpl{}
+
+const (
+	// indexWorkers 更新全文索引的协程数, 同一条消息的索引由同一个协程按顺序更新
+	indexWorkers = 8
+	// indexQueueSize 每个协程的等待队列长度, 队列满时在调用者中直接更新
+	indexQueueSize = 4096
+)
+
+type indexTask struct {
+	mid     int64
+	group   bool
+	content string
+	remove  bool
+}
+
+var (
+	indexQueues [indexWorkers]chan *indexTask
+	indexOnce   sync.Once
+)
+
+type searchDaoImpl struct {
+}
+
+func startIndexWorkers() {
+	for i := range indexQueues {
+		q := make(chan *indexTask, indexQueueSize)
+		indexQueues[i] = q
+		go func() {
+			for t := range q {
+				runIndexTask(t)
+			}
+		}()
+	}
+}
+
+// submitIndex 将索引更新交给后台协程异步执行, 不阻塞消息保存
+func submitIndex(t *indexTask) {
+	indexOnce.Do(startIndexWorkers)
+	q := indexQueues[uint64(t.mid)%indexWorkers]
+	select {
+	case q <- t:
+	default:
+		logger.W("message index queue is full, mid=%d", t.mid)
+		runIndexTask(t)
+	}
+}
+
+func runIndexTask(t *indexTask) {
+	var err error
+	if t.remove {
+		err = SearchDaoImpl.RemoveIndex(t.mid)
+	} else {
+		err = SearchDaoImpl.IndexMessage(t.mid, t.group, t.content)
+	}
+	if err != nil {
+		logger.E("update message index error, mid=%d, %v", t.mid, err)
+	}
+}
+
+// indexMessage 保存消息后异步更新全文索引, 索引失败不影响消息保存
+func indexMessage(mid int64, group bool, content string) {
+	submitIndex(&indexTask{mid: mid, group: group, content: content})
+}
+
+func removeIndex(mid ...int64) {
+	for _, m := range mid {
+		submitIndex(&indexTask{mid: m, remove: true})
+	}
+}
+
+func (searchDaoImpl) IndexMessage(mid int64, group bool, content string) error {
+	tokens := search.Tokenize(content)
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := common.JustError(tx.Where("m_id = ?", mid).Delete(&MessageToken{})); err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			return nil
+		}
+		var ts []*MessageToken
+		for _, t := range tokens {
+			ts = append(ts, &MessageToken{Token: t, MID: mid, Group: group})
+		}
+		return common.JustError(tx.Create(&ts))
+	})
+}
+
+func (searchDaoImpl) RemoveIndex(mid ...int64) error {
+	if len(mid) == 0 {
+		return nil
+	}
+	query := db.DB.Where("m_id IN (?)", mid).Delete(&MessageToken{})
+	return common.JustError(query)
+}
+
+func (searchDaoImpl) SearchChatMessages(uid int64, f *SearchFilter) ([]*ChatMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*ChatMessage{}
+	if len(f.Tokens) == 0 {
+		return ms, nil
+	}
+	query := db.DB.Model(&ChatMessage{}).
+		Where("`m_id` IN (?)", matchedMessages(f.Tokens, false)).
+		Where("(`from` = ? OR `to` = ?) AND `status` <> ?", uid, uid, ChatMessageStatusRecalled).
+		Scopes(visibleChatMessages(uid))
+	if f.To != 0 {
+		query = query.Where("((`from` = ? AND `to` = ?) OR (`from` = ? AND `to` = ?))", uid, f.To, f.To, uid)
+	}
+	query = applySearchFilter(query, f, "create_at")
+	query = query.Order("`m_id` DESC").Limit(f.Limit).Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (searchDaoImpl) SearchGroupMessages(uid int64, gid []int64, f *SearchFilter) ([]*GroupMessage, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*GroupMessage{}
+	if len(f.Tokens) == 0 || len(gid) == 0 {
+		return ms, nil
+	}
+	query := db.DB.Model(&GroupMessage{}).
+		Where("`m_id` IN (?)", matchedMessages(f.Tokens, true)).
+		Where("`to` IN (?) AND `status` <> ?", gid, ChatMessageStatusRecalled).
+		Scopes(visibleGroupMessages(uid))
+	query = applySearchFilter(query, f, "send_at")
+	query = query.Order("`m_id` DESC").Limit(f.Limit).Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// matchedMessages 包含所有词的消息 ID 子查询
+func matchedMessages(tokens []string, group bool) *gorm.DB {
+	return db.DB.Model(&MessageToken{}).
+		Select("m_id").
+		Where("`token` IN (?) AND `group` = ?", tokens, group).
+		Group("m_id").
+		Having("COUNT(DISTINCT `token`) = ?", len(tokens))
+}
+
+func applySearchFilter(query *gorm.DB, f *SearchFilter, timeColumn string) *gorm.DB {
+	if f.From != 0 {
+		query = query.Where("`from` = ?", f.From)
+	}
+	if f.Type != 0 {
+		query = query.Where("`type` = ?", f.Type)
+	}
+	if f.StartAt > 0 {
+		query = query.Where("`"+timeColumn+"` >= ?", f.StartAt)
+	}
+	if f.EndAt > 0 {
+		query = query.Where("`"+timeColumn+"` <= ?", f.EndAt)
+	}
+	if f.BeforeMid > 0 {
+		query = query.Where("`m_id` < ?", f.BeforeMid)
+	}
+	return query
+}
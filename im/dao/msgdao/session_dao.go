@@ -1,6 +1,7 @@
 package msgdao
 
 import (
+	"errors"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/pkg/logger"
 	"github.com/go-redis/redis"
@@ -16,6 +17,11 @@ const (
 
 var SessionDaoImpl SessionDao = &sessionDaoImpl{}
 
+var (
+	ErrSessionNotExist = errors.New("session not exist")
+	ErrReadMidInvalid  = errors.New("message is not in the session")
+)
+
 type sessionDaoImpl struct{}
 
 func getSessionId(uid1 int64, uid2 int64) (string, int64, int64) {
@@ -77,6 +83,110 @@ func (s *sessionDaoImpl) CleanUserSessionUnread(uid1, uid2 int64, uid int64) err
 	return err
 }
 
+// updateReadScript 原子地更新已读位置并清空未读数, 会话不存在时返回 nil, 不创建不完整的会话.
+// ARGV[3] 为 0 时读到会话最后一条消息, 已读位置只前进不后退
+var updateReadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return nil
+end
+local mid = ARGV[3]
+if tonumber(mid) == 0 then
+	mid = redis.call('HGET', KEYS[1], 'l_mid') or '0'
+end
+local read = redis.call('HGET', KEYS[1], ARGV[2]) or '0'
+if tonumber(mid) < tonumber(read) then
+	mid = read
+end
+redis.call('HMSET', KEYS[1], ARGV[1], 0, ARGV[2], mid)
+return mid
+`)
+
+func (s *sessionDaoImpl) UpdateUserSessionRead(uid1, uid2 int64, uid int64, mid int64) (int64, error) {
+	id, lg, _ := getSessionId(uid1, uid2)
+	unreadKey, readKey := "lg_unread", "lg_read"
+	if lg != uid {
+		unreadKey, readKey = "sm_unread", "sm_read"
+	}
+	if mid != 0 {
+		ms, err := instance.GetChatMessage(mid)
+		if err != nil {
+			return 0, err
+		}
+		if len(ms) == 0 || !((ms[0].From == uid1 && ms[0].To == uid2) || (ms[0].From == uid2 && ms[0].To == uid1)) {
+			return 0, ErrReadMidInvalid
+		}
+	}
+	result, err := updateReadScript.Run(db.Redis, []string{keySession + id}, unreadKey, readKey, mid).String()
+	if err == redis.Nil {
+		return 0, ErrSessionNotExist
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(result, 10, 64)
+}
+
+func (s *sessionDaoImpl) GetSessionTTL(uid1 int64, uid2 int64) (int64, error) {
+	id, _, _ := getSessionId(uid1, uid2)
+	ttl, err := db.Redis.HGet(keySession+id, "ttl").Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return ttl, err
+}
+
+func (s *sessionDaoImpl) SetSessionTTL(uid1 int64, uid2 int64, ttl int64) error {
+	id, _, _ := getSessionId(uid1, uid2)
+	exist, err := db.Redis.Exists(keySession + id).Result()
+	if err != nil {
+		return err
+	}
+	// 会话不存在时先创建完整的会话, 避免只有 ttl 字段且不过期的会话
+	if exist == 0 {
+		if _, err = s.CreateSession(uid1, uid2, time.Now().Unix()); err != nil {
+			return err
+		}
+	}
+	_, err = db.Redis.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(keySession+id, "ttl", ttl)
+		pipe.ExpireAt(keySession+id, time.Now().Add(time.Hour*24*30))
+		return nil
+	})
+	return err
+}
+
+func (s *sessionDaoImpl) GetUnreadCount(uid int64, limit int64) (int64, error) {
+	sids, err := db.Redis.ZRevRange(keyUserSessions+strconv.FormatInt(uid, 10), 0, limit-1).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(sids) == 0 {
+		return 0, nil
+	}
+	pipe := db.Redis.Pipeline()
+	var cmds []*redis.StringCmd
+	for _, sid := range sids {
+		lg, _ := sid2Uid(sid)
+		key := "sm_unread"
+		if lg == uid {
+			key = "lg_unread"
+		}
+		cmds = append(cmds, pipe.HGet(keySession+sid, key))
+	}
+	_, err = pipe.Exec()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	var count int64
+	for _, cmd := range cmds {
+		n, err := cmd.Int64()
+		if err == nil && n > 0 {
+			count += n
+		}
+	}
+	return count, nil
+}
+
 func (s *sessionDaoImpl) GetSession(uid int64, uid2 int64) (*Session, error) {
 	sid, lg, sm := getSessionId(uid, uid2)
 	result, err := db.Redis.HGetAll(keySession + sid).Result()
@@ -93,6 +203,9 @@ func (s *sessionDaoImpl) GetSession(uid int64, uid2 int64) (*Session, error) {
 		Uid2:        sm,
 		LgUidUnread: getInt64FromMap(result, "lg_unread"),
 		SmUidUnread: getInt64FromMap(result, "sm_unread"),
+		LgUidRead:   getInt64FromMap(result, "lg_read"),
+		SmUidRead:   getInt64FromMap(result, "sm_read"),
+		TTL:         getInt64FromMap(result, "ttl"),
 		LastMID:     getInt64FromMap(result, "l_mid"),
 		UpdateAt:    getInt64FromMap(result, "update"),
 		CreateAt:    getInt64FromMap(result, "create"),
@@ -113,6 +226,9 @@ func (s *sessionDaoImpl) CreateSession(uid1 int64, uid2 int64, updateAt int64) (
 	_, err = db.Redis.HMSet(keySession+sid, map[string]interface{}{
 		"lg_unread": 0,
 		"sm_unread": 0,
+		"lg_read":   0,
+		"sm_read":   0,
+		"ttl":       0,
 		"l_mid":     "0",
 		"update":    updateAt,
 		"create":    updateAt,
@@ -170,7 +286,7 @@ func (s *sessionDaoImpl) UpdateOrCreateSession(uid1 int64, uid2 int64, sender in
 		"l_mid": mid,
 		// TODO 2021-12-28 NOTE: do business here, clean sender's unread
 		key:      0,
-		"update": sender,
+		"update": sendAt,
 	}
 	_, err = db.Redis.HMSet(keySession+sid, m).Result()
 	db.Redis.ExpireAt(keySession+sid, time.Now().Add(time.Hour*24*30))
@@ -0,0 +1,99 @@
+package msgdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var VisibilityDaoImpl VisibilityDao = visibilityDaoImpl{}
+
+type visibilityDaoImpl struct {
+}
+
+func (visibilityDaoImpl) HideMessages(uid int64, mid ...int64) error {
+	if len(mid) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	var hs []*MessageHidden
+	for _, m := range mid {
+		hs = append(hs, &MessageHidden{UID: uid, MID: m, CreateAt: now})
+	}
+	query := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&hs)
+	return common.JustError(query)
+}
+
+func (visibilityDaoImpl) GetHiddenMessages(uid int64, mid ...int64) ([]int64, error) {
+	//goland:noinspection GoPreferNilSlice
+	hidden := []int64{}
+	if len(mid) == 0 {
+		return hidden, nil
+	}
+	query := db.DB.Model(&MessageHidden{}).
+		Where("`uid` = ? AND `m_id` IN (?)", uid, mid).
+		Pluck("m_id", &hidden)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return hidden, nil
+}
+
+func (visibilityDaoImpl) ClearHistory(uid int64, target int64, group bool, position int64) error {
+	c := &MessageClear{
+		UID:      uid,
+		Target:   target,
+		Group:    group,
+		Position: position,
+		UpdateAt: time.Now().Unix(),
+	}
+	// 记录不存在时创建, 清空位置只前进不后退
+	query := db.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"position":  gorm.Expr("GREATEST(`position`, VALUES(`position`))"),
+			"update_at": gorm.Expr("VALUES(`update_at`)"),
+		}),
+	}).Create(c)
+	return common.JustError(query)
+}
+
+func (visibilityDaoImpl) GetClearPositions(uid int64, group bool, target ...int64) (map[int64]int64, error) {
+	positions := map[int64]int64{}
+	if len(target) == 0 {
+		return positions, nil
+	}
+	var cs []*MessageClear
+	query := db.DB.Model(&MessageClear{}).
+		Where("`uid` = ? AND `group` = ? AND `target` IN (?)", uid, group, target).
+		Find(&cs)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	for _, c := range cs {
+		positions[c.Target] = c.Position
+	}
+	return positions, nil
+}
+
+// visibleChatMessages 查询条件, 排除用户删除的和清空位置及之前的单聊消息, 单聊的清空位置为消息创建时间
+func visibleChatMessages(uid int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.
+			Where("NOT EXISTS (SELECT 1 FROM `im_message_hidden` h WHERE h.`uid` = ? AND h.`m_id` = `im_chat_message`.`m_id`)", uid).
+			Where("NOT EXISTS (SELECT 1 FROM `im_message_clear` c WHERE c.`uid` = ? AND c.`group` = ? "+
+				"AND c.`target` = IF(`im_chat_message`.`from` = ?, `im_chat_message`.`to`, `im_chat_message`.`from`) "+
+				"AND `im_chat_message`.`create_at` <= c.`position`)", uid, false, uid)
+	}
+}
+
+// visibleGroupMessages 查询条件, 排除用户删除的和清空位置及之前的群消息, 群的清空位置为群消息 seq
+func visibleGroupMessages(uid int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.
+			Where("NOT EXISTS (SELECT 1 FROM `im_message_hidden` h WHERE h.`uid` = ? AND h.`m_id` = `im_group_message`.`m_id`)", uid).
+			Where("NOT EXISTS (SELECT 1 FROM `im_message_clear` c WHERE c.`uid` = ? AND c.`group` = ? "+
+				"AND c.`target` = `im_group_message`.`to` AND `im_group_message`.`seq` <= c.`position`)", uid, true)
+	}
+}
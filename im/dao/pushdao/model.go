@@ -0,0 +1,35 @@
+package pushdao
+
+// PushToken 设备的推送 token, 每个用户的每个设备一个
+type PushToken struct {
+	ID     int64 `gorm:"primaryKey"`
+	Uid    int64
+	Device int64
+	// Platform 推送平台, apns, fcm 或 webpush
+	Platform string
+	Token    string
+	CreateAt int64
+	UpdateAt int64
+}
+
+// PushSetting 用户的推送设置
+type PushSetting struct {
+	Uid int64 `gorm:"primaryKey"`
+	// DndStart, DndEnd 免打扰时段, 一天中的分钟数, 相等时不开启, DndStart 大于 DndEnd 时跨越零点
+	DndStart int
+	DndEnd   int
+	// TzOffset 用户时区相对 UTC 的偏移, 单位分钟
+	TzOffset int
+	// HidePreview 通知中不显示消息内容
+	HidePreview bool
+	UpdateAt    int64
+}
+
+// PushMute 不推送通知的会话
+type PushMute struct {
+	ID       int64 `gorm:"primaryKey"`
+	Uid      int64
+	Target   int64
+	Group    bool
+	CreateAt int64
+}
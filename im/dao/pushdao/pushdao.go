@@ -0,0 +1,122 @@
+package pushdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"time"
+)
+
+const (
+	PlatformAPNs    = "apns"
+	PlatformFCM     = "fcm"
+	PlatformWebPush = "webpush"
+)
+
+var Dao PushDao = pushDaoImpl{}
+
+type PushDao interface {
+	// SaveToken 保存设备的推送 token, 替换该设备原有的 token, 同一 token 只属于最后注册的用户
+	SaveToken(t *PushToken) error
+	RemoveToken(uid int64, device int64) error
+	// RemoveTokenByValue 删除推送平台报告失效的 token
+	RemoveTokenByValue(platform string, token string) error
+	GetTokens(uid int64) ([]*PushToken, error)
+
+	// GetSetting 获取用户的推送设置, 未设置时返回默认设置
+	GetSetting(uid int64) (*PushSetting, error)
+	SaveSetting(s *PushSetting) error
+
+	SetMute(uid int64, target int64, group bool, mute bool) error
+	IsMuted(uid int64, target int64, group bool) (bool, error)
+	GetMutes(uid int64) ([]*PushMute, error)
+}
+
+type pushDaoImpl struct {
+}
+
+func (pushDaoImpl) SaveToken(t *PushToken) error {
+	query := db.DB.Where("(`uid` = ? AND `device` = ?) OR (`platform` = ? AND `token` = ?)", t.Uid, t.Device, t.Platform, t.Token).
+		Delete(&PushToken{})
+	if err := common.JustError(query); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	t.CreateAt = now
+	t.UpdateAt = now
+	query = db.DB.Create(t)
+	return common.ResolveError(query)
+}
+
+func (pushDaoImpl) RemoveToken(uid int64, device int64) error {
+	query := db.DB.Where("`uid` = ? AND `device` = ?", uid, device).Delete(&PushToken{})
+	return common.JustError(query)
+}
+
+func (pushDaoImpl) RemoveTokenByValue(platform string, token string) error {
+	query := db.DB.Where("`platform` = ? AND `token` = ?", platform, token).Delete(&PushToken{})
+	return common.JustError(query)
+}
+
+func (pushDaoImpl) GetTokens(uid int64) ([]*PushToken, error) {
+	//goland:noinspection GoPreferNilSlice
+	ts := []*PushToken{}
+	query := db.DB.Model(&PushToken{}).Where("`uid` = ?", uid).Find(&ts)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (pushDaoImpl) GetSetting(uid int64) (*PushSetting, error) {
+	s := &PushSetting{}
+	query := db.DB.Model(s).Where("`uid` = ?", uid).Find(s)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	s.Uid = uid
+	return s, nil
+}
+
+func (pushDaoImpl) SaveSetting(s *PushSetting) error {
+	s.UpdateAt = time.Now().Unix()
+	query := db.DB.Save(s)
+	return common.JustError(query)
+}
+
+func (pushDaoImpl) SetMute(uid int64, target int64, group bool, mute bool) error {
+	query := db.DB.Where("`uid` = ? AND `target` = ? AND `group` = ?", uid, target, group).Delete(&PushMute{})
+	if err := common.JustError(query); err != nil {
+		return err
+	}
+	if !mute {
+		return nil
+	}
+	query = db.DB.Create(&PushMute{
+		Uid:      uid,
+		Target:   target,
+		Group:    group,
+		CreateAt: time.Now().Unix(),
+	})
+	return common.ResolveError(query)
+}
+
+func (pushDaoImpl) IsMuted(uid int64, target int64, group bool) (bool, error) {
+	var count int64
+	query := db.DB.Model(&PushMute{}).
+		Where("`uid` = ? AND `target` = ? AND `group` = ?", uid, target, group).
+		Count(&count)
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (pushDaoImpl) GetMutes(uid int64) ([]*PushMute, error) {
+	//goland:noinspection GoPreferNilSlice
+	ms := []*PushMute{}
+	query := db.DB.Model(&PushMute{}).Where("`uid` = ?", uid).Find(&ms)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
@@ -1,9 +1,10 @@
 package uid
 
 import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/idgen"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/pkg/logger"
-	"strconv"
 	"sync"
 )
 
@@ -11,6 +12,12 @@ const keyTempIdIncr = "im:uid:temp:incr"
 const keyUidIncr = "im:uid:incr"
 const keySystemIdIncr = "im:uid:sys:incr"
 
+const (
+	segmentTagTempId = "uid_temp"
+	segmentTagUid    = "uid"
+	segmentTagSysId  = "uid_sys"
+)
+
 const (
 	systemIdStart = 1_000
 	systemIdEnd   = systemIdStart + 100_000
@@ -23,9 +30,46 @@ const (
 )
 
 func Init() {
-	checkIncrKey(keyTempIdIncr, tempIdStart+1)
-	checkIncrKey(keySystemIdIncr, systemIdStart+1)
-	checkIncrKey(keyUidIncr, userIdStart+1)
+	if config.IdGen.UidMode == config.IdGenModeRedis {
+		checkIncrKey(keyTempIdIncr, tempIdStart+1)
+		checkIncrKey(keySystemIdIncr, systemIdStart+1)
+		checkIncrKey(keyUidIncr, userIdStart+1)
+		return
+	}
+	instance = &allocatorGen{
+		sysUid:  newSegmentAllocator(segmentTagSysId, keySystemIdIncr, systemIdStart+1, systemIdEnd, false, "im_user", "im_bot"),
+		uid:     newSegmentAllocator(segmentTagUid, keyUidIncr, userIdStart+1, userIdEnd, false, "im_user"),
+		tempUid: newSegmentAllocator(segmentTagTempId, keyTempIdIncr, tempIdStart+1, tempIdEnd, true),
+	}
+}
+
+// newSegmentAllocator 创建号段分配器, 号段记录从 Redis 中原有的计数和 tables 中已保存的最大 uid 之后开始, 避免与已分配的 ID 重复
+func newSegmentAllocator(tag string, redisKey string, start int64, end int64, cycle bool, tables ...string) idgen.Allocator {
+	var seed int64
+	n, err := db.Redis.Get(redisKey).Int64()
+	if err == nil && n >= start && n < end {
+		seed = n
+	}
+	for _, t := range tables {
+		n, err = idgen.MaxTableID(t, "uid", start, end)
+		if err != nil {
+			panic(err)
+		}
+		if n > seed {
+			seed = n
+		}
+	}
+	if seed > 0 {
+		err = idgen.MySqlSegmentStore.Init(tag, seed)
+		if err != nil {
+			panic(err)
+		}
+	}
+	a, err := idgen.NewSegmentAllocator(idgen.MySqlSegmentStore, tag, start, end, config.IdGen.SegmentStep, cycle)
+	if err != nil {
+		panic(err)
+	}
+	return a
 }
 
 func checkIncrKey(key string, initialValue int64) {
@@ -65,13 +109,14 @@ var instance Gen = &gen{
 }
 
 func (g *gen) GenSysUid() int64 {
-	rs, err := g.getInt64(keyTempIdIncr)
+	result, err := db.Redis.Incr(keySystemIdIncr).Result()
 	if err != nil {
 		return 0
 	}
-	next := rs + 1
-	db.Redis.Set(keySystemIdIncr, next, 0)
-	return next
+	if result >= systemIdEnd {
+		logger.E("system uid exhausted")
+	}
+	return result
 }
 
 func (g *gen) GenUid() int64 {
@@ -98,18 +143,32 @@ func (g *gen) GenTempUid() int64 {
 	return result
 }
 
-func (g *gen) getInt64(key string) (int64, error) {
-	r, err := db.Redis.Get(key).Result()
-	if err != nil {
-		logger.E("get redis error", err)
-		return 0, err
-	}
-	rs, err := strconv.ParseInt(r, 10, 64)
+// allocatorGen 使用 ID 分配器生成各范围的 uid
+type allocatorGen struct {
+	sysUid  idgen.Allocator
+	uid     idgen.Allocator
+	tempUid idgen.Allocator
+}
+
+func (g *allocatorGen) GenSysUid() int64 {
+	return g.next(g.sysUid)
+}
+
+func (g *allocatorGen) GenUid() int64 {
+	return g.next(g.uid)
+}
+
+func (g *allocatorGen) GenTempUid() int64 {
+	return g.next(g.tempUid)
+}
+
+func (g *allocatorGen) next(a idgen.Allocator) int64 {
+	id, err := a.NextID()
 	if err != nil {
-		logger.E("conv redis value to int64 err", err)
-		return 0, err
+		logger.E("generate uid error %v", err)
+		return 0
 	}
-	return rs, nil
+	return id
 }
 
 func GenSysUid() int64 {
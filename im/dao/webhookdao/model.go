@@ -0,0 +1,55 @@
+package webhookdao
+
+const (
+	DeliveryStatusPending = 0
+	DeliveryStatusSuccess = 1
+	// DeliveryStatusDead 重试次数用尽, 进入死信列表, 可以手动重新投递
+	DeliveryStatusDead = 2
+)
+
+// Webhook 事件订阅, 订阅的事件发生时向 Url 推送
+type Webhook struct {
+	ID  int64 `gorm:"primaryKey"`
+	Url string
+	// Secret 推送签名密钥
+	Secret string
+	// Events 订阅的事件, 逗号分隔, * 表示所有事件
+	Events      string
+	Description string
+	Enabled     bool
+	// Bot 是否为机器人的推送订阅, 机器人的订阅始终不允许推送到内网
+	Bot      bool
+	CreateAt int64
+	UpdateAt int64
+}
+
+// WebhookDelivery 一个事件向一个订阅的推送, 失败后按退避时间重试
+type WebhookDelivery struct {
+	ID        int64 `gorm:"primaryKey"`
+	WebhookID int64
+	// EventID 事件 ID, 同一事件推送给不同订阅时相同, 接收方可以用于去重
+	EventID string
+	Event   string
+	// Payload 推送的请求体, 重试时不变
+	Payload  string
+	Status   int
+	Attempts int
+	// NextAttemptAt 下次推送的时间, 正在推送时为租约到期时间
+	NextAttemptAt int64
+	LastError     string
+	CreateAt      int64
+	UpdateAt      int64
+}
+
+// WebhookAttempt 一次推送尝试的记录
+type WebhookAttempt struct {
+	ID         int64 `gorm:"primaryKey"`
+	DeliveryID int64
+	Attempt    int
+	// StatusCode 响应状态码, 请求失败时为 0
+	StatusCode int
+	Error      string
+	// Duration 请求耗时, 毫秒
+	Duration int64
+	CreateAt int64
+}
@@ -0,0 +1,176 @@
+package webhookdao
+
+import (
+	"github.com/glide-im/glideim/im/dao/common"
+	"github.com/glide-im/glideim/pkg/db"
+	"time"
+)
+
+var Dao WebhookDao = webhookDaoImpl{}
+
+type WebhookDao interface {
+	CreateWebhook(w *Webhook) error
+	// UpdateWebhook 更新订阅的地址, 事件, 描述和启用状态
+	UpdateWebhook(w *Webhook) error
+	DeleteWebhook(id int64) error
+	GetWebhook(id int64) (*Webhook, error)
+	// GetWebhooks 获取所有订阅, enabledOnly 为 true 时只返回启用的订阅
+	GetWebhooks(enabledOnly bool) ([]*Webhook, error)
+
+	AddDeliveries(ds []*WebhookDelivery) error
+	GetDelivery(id int64) (*WebhookDelivery, error)
+	// GetDueDeliveries 获取到期需要推送的记录
+	GetDueDeliveries(now int64, limit int) ([]*WebhookDelivery, error)
+	// ClaimDelivery 仅当下次推送时间为 expect 时将其更新为租约到期时间 until, 返回是否抢占成功, 用于多个节点同时推送时去重
+	ClaimDelivery(id int64, expect int64, until int64) (bool, error)
+	// UpdateDelivery 更新推送状态, 次数, 下次推送时间和最后的错误
+	UpdateDelivery(d *WebhookDelivery) error
+	// GetDeliveries 按 ID 降序获取订阅的推送记录, webhookId 为 0 时返回所有订阅的记录, status 小于 0 时不过滤状态
+	GetDeliveries(webhookId int64, status int, beforeId int64, limit int) ([]*WebhookDelivery, error)
+
+	AddAttempt(a *WebhookAttempt) error
+	GetAttempts(deliveryId int64) ([]*WebhookAttempt, error)
+}
+
+type webhookDaoImpl struct {
+}
+
+func (webhookDaoImpl) CreateWebhook(w *Webhook) error {
+	query := db.DB.Create(w)
+	return common.ResolveError(query)
+}
+
+func (webhookDaoImpl) UpdateWebhook(w *Webhook) error {
+	w.UpdateAt = time.Now().Unix()
+	query := db.DB.Model(&Webhook{}).
+		Where("`id` = ?", w.ID).
+		Updates(map[string]interface{}{
+			"url":         w.Url,
+			"events":      w.Events,
+			"description": w.Description,
+			"enabled":     w.Enabled,
+			"update_at":   w.UpdateAt,
+		})
+	return common.JustError(query)
+}
+
+func (webhookDaoImpl) DeleteWebhook(id int64) error {
+	query := db.DB.Where("`id` = ?", id).Delete(&Webhook{})
+	return common.MustUpdate(query)
+}
+
+func (webhookDaoImpl) GetWebhook(id int64) (*Webhook, error) {
+	w := &Webhook{}
+	query := db.DB.Model(w).Where("`id` = ?", id).Find(w)
+	if err := common.MustFind(query); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (webhookDaoImpl) GetWebhooks(enabledOnly bool) ([]*Webhook, error) {
+	//goland:noinspection GoPreferNilSlice
+	ws := []*Webhook{}
+	query := db.DB.Model(&Webhook{})
+	if enabledOnly {
+		query = query.Where("`enabled` = ?", true)
+	}
+	query = query.Order("`id` ASC").Find(&ws)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+func (webhookDaoImpl) AddDeliveries(ds []*WebhookDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	query := db.DB.Create(&ds)
+	return common.ResolveError(query)
+}
+
+func (webhookDaoImpl) GetDelivery(id int64) (*WebhookDelivery, error) {
+	d := &WebhookDelivery{}
+	query := db.DB.Model(d).Where("`id` = ?", id).Find(d)
+	if err := common.MustFind(query); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (webhookDaoImpl) GetDueDeliveries(now int64, limit int) ([]*WebhookDelivery, error) {
+	//goland:noinspection GoPreferNilSlice
+	ds := []*WebhookDelivery{}
+	query := db.DB.Model(&WebhookDelivery{}).
+		Where("`status` = ? AND `next_attempt_at` <= ?", DeliveryStatusPending, now).
+		Order("`next_attempt_at` ASC").
+		Limit(limit).
+		Find(&ds)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (webhookDaoImpl) ClaimDelivery(id int64, expect int64, until int64) (bool, error) {
+	query := db.DB.Model(&WebhookDelivery{}).
+		Where("`id` = ? AND `status` = ? AND `next_attempt_at` = ?", id, DeliveryStatusPending, expect).
+		Update("next_attempt_at", until)
+	if err := common.JustError(query); err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
+
+func (webhookDaoImpl) UpdateDelivery(d *WebhookDelivery) error {
+	d.UpdateAt = time.Now().Unix()
+	query := db.DB.Model(&WebhookDelivery{}).
+		Where("`id` = ?", d.ID).
+		Updates(map[string]interface{}{
+			"status":          d.Status,
+			"attempts":        d.Attempts,
+			"next_attempt_at": d.NextAttemptAt,
+			"last_error":      d.LastError,
+			"update_at":       d.UpdateAt,
+		})
+	return common.JustError(query)
+}
+
+func (webhookDaoImpl) GetDeliveries(webhookId int64, status int, beforeId int64, limit int) ([]*WebhookDelivery, error) {
+	//goland:noinspection GoPreferNilSlice
+	ds := []*WebhookDelivery{}
+	query := db.DB.Model(&WebhookDelivery{})
+	if webhookId != 0 {
+		query = query.Where("`webhook_id` = ?", webhookId)
+	}
+	if status >= 0 {
+		query = query.Where("`status` = ?", status)
+	}
+	if beforeId > 0 {
+		query = query.Where("`id` < ?", beforeId)
+	}
+	query = query.Order("`id` DESC").Limit(limit).Find(&ds)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (webhookDaoImpl) AddAttempt(a *WebhookAttempt) error {
+	query := db.DB.Create(a)
+	return common.ResolveError(query)
+}
+
+func (webhookDaoImpl) GetAttempts(deliveryId int64) ([]*WebhookAttempt, error) {
+	//goland:noinspection GoPreferNilSlice
+	as := []*WebhookAttempt{}
+	query := db.DB.Model(&WebhookAttempt{}).
+		Where("`delivery_id` = ?", deliveryId).
+		Order("`attempt` ASC").
+		Find(&as)
+	if err := common.JustError(query); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
@@ -2,8 +2,14 @@ package group
 
 import (
 	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/bot"
+	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/dao/uid"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/push"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/logger"
 	"github.com/glide-im/glideim/pkg/timingwheel"
 	"github.com/panjf2000/ants/v2"
@@ -14,10 +20,15 @@ import (
 )
 
 type memberInfo struct {
-	online    bool
-	muted     bool
-	admin     bool
-	deletedAt int64
+	online bool
+	muted  bool
+	admin  bool
+	// bot 机器人成员, 不下发消息到客户端, 消息由 bot 包投递
+	bot        bool
+	botReadAll bool
+	deletedAt  int64
+	// ackSeq 成员最后确认的消息 seq, 0 表示未知
+	ackSeq int64
 }
 
 func newMemberInfo() *memberInfo {
@@ -32,7 +43,10 @@ func newMemberInfo() *memberInfo {
 var tw = timingwheel.NewTimingWheel(time.Second, 3, 20)
 var queueExec *ants.Pool
 
-// group message sequence segment length
+// pushExec 离线推送协程池, 查询成员在线状态可能需要 rpc, 不能在消息队列中执行
+var pushExec *ants.Pool
+
+// msgSeqSegmentLen 新建群 seq 记录时的号段长度, 已有记录以数据库中的步长为准
 const msgSeqSegmentLen = 200
 
 const messageQueueSleep = time.Second * 10
@@ -47,6 +61,14 @@ func init() {
 	if e != nil {
 		panic(e)
 	}
+	pushExec, e = ants.NewPool(1000,
+		ants.WithNonblocking(true),
+		ants.WithPreAlloc(false),
+		ants.WithPanicHandler(onQueueExecutorPanic),
+	)
+	if e != nil {
+		panic(e)
+	}
 }
 
 func onQueueExecutorPanic(i interface{}) {
@@ -56,16 +78,23 @@ func onQueueExecutorPanic(i interface{}) {
 type Group struct {
 	gid int64
 
+	// msgSequence 最后分配的消息 seq, seqMax 当前号段的最大 seq, 号段用尽时从数据库租用新号段,
+	// seqLoaded 是否已从数据库恢复 seq
 	msgSequence int64
-	seqRemain   int64
+	seqMax      int64
+	seqLoaded   bool
+	seqMu       *sync.Mutex
 
 	startup string
 
 	mute      bool
 	dissolved bool
 
+	// msgTTL 群消息存活时间, 单位秒, 0 表示不过期
+	msgTTL int64
+
 	// messages 群消息队列
-	messages chan *message.ChatMessage
+	messages chan *queuedMessage
 	// notify 群通知队列
 	notify chan *message.GroupNotify
 
@@ -76,27 +105,40 @@ type Group struct {
 	checkActive *timingwheel.Task
 
 	lastMsgAt time.Time
-	mu        *sync.Mutex
-	members   map[int64]*memberInfo
+
+	// readFrom 已读人数发生变化的最小 seq, readNotifying 是否已经计划推送已读人数
+	readFrom      int64
+	readNotifying bool
+
+	mu      *sync.Mutex
+	members map[int64]*memberInfo
 }
 
-func newGroup(gid int64, seq int64) *Group {
+// queuedMessage 群消息队列中的消息, action 为写入成员收件箱的事件
+type queuedMessage struct {
+	action message.Action
+	msg    *message.ChatMessage
+}
+
+func newGroup(gid int64) *Group {
 	ret := new(Group)
 	ret.mu = &sync.Mutex{}
 	ret.members = map[int64]*memberInfo{}
 	ret.startup = strconv.FormatInt(time.Now().Unix(), 10)
-	ret.messages = make(chan *message.ChatMessage, 100)
+	ret.messages = make(chan *queuedMessage, 100)
 	ret.notify = make(chan *message.GroupNotify, 10)
 	ret.checkActive = tw.After(messageQueueSleep)
 	ret.queueRunning = 0
-	ret.msgSequence = seq
-	ret.seqRemain = msgSeqSegmentLen
+	ret.seqMu = &sync.Mutex{}
 	ret.gid = gid
 	return ret
 }
 
 func (g *Group) EnqueueNotify(msg *message.GroupNotify) error {
-	seq := atomic.AddInt64(&g.msgSequence, 1)
+	seq, err := g.nextSeq()
+	if err != nil {
+		return err
+	}
 	msg.Seq = seq
 	select {
 	case g.notify <- msg:
@@ -114,7 +156,11 @@ func (g *Group) EnqueueMessage(msg *message.ChatMessage, recall bool) (int64, er
 	g.mu.Unlock()
 
 	if !exist {
-		return 0, errors.New("not a group member")
+		// 系统账号不是群成员也可以发送消息, 机器人需要先加入群
+		if !uid.IsSystemId(msg.From) || bot.IsBot(msg.From) {
+			return 0, errors.New("not a group member")
+		}
+		mf = newMemberInfo()
 	}
 	if mf.muted && !recall {
 		return 0, errors.New("a muted group member send message")
@@ -126,8 +172,8 @@ func (g *Group) EnqueueMessage(msg *message.ChatMessage, recall bool) (int64, er
 		if err != nil {
 			return 0, err
 		}
-		if r.RecallBy != msg.From && !mf.admin {
-			return 0, errors.New("illegal operation")
+		if err = g.checkRecall(mf, msg.From, r); err != nil {
+			return 0, err
 		}
 		err = msgdao.GroupMsgDaoImpl.UpdateGroupMessageRecall(g.gid, r.Mid, msgdao.ChatMessageStatusRecalled, r.RecallBy)
 		if err != nil {
@@ -135,21 +181,32 @@ func (g *Group) EnqueueMessage(msg *message.ChatMessage, recall bool) (int64, er
 		}
 	}
 	now := time.Now().Unix()
-	seq := atomic.AddInt64(&g.msgSequence, 1)
-	err := msgdao.AddGroupMessage(&msgdao.GroupMessage{
-		MID:     msg.Mid,
-		Seq:     seq,
-		To:      g.gid,
-		From:    msg.From,
-		Type:    msg.Type,
-		SendAt:  now,
-		Content: msg.Content,
+	ttl := atomic.LoadInt64(&g.msgTTL)
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = now + ttl
+	}
+	seq, err := g.nextSeq()
+	if err != nil {
+		return 0, err
+	}
+	err = msgdao.AddGroupMessage(&msgdao.GroupMessage{
+		MID:      msg.Mid,
+		Seq:      seq,
+		To:       g.gid,
+		From:     msg.From,
+		Type:     msg.Type,
+		SendAt:   now,
+		Content:  msg.Content,
+		ExpireAt: expireAt,
+		CreateAt: now,
 	})
 	if err != nil {
-		atomic.AddInt64(&g.msgSequence, -1)
 		return 0, err
 	}
-	g.checkSeqRemain()
+	if ttl > 0 && ttl <= config.Messaging.ExpireNearTerm {
+		g.scheduleExpire(msg.Mid, msg.From, ttl)
+	}
 
 	err = msgdao.UpdateGroupMessageState(g.gid, msg.Mid, time.Now().Unix(), seq)
 	if err != nil {
@@ -164,9 +221,16 @@ func (g *Group) EnqueueMessage(msg *message.ChatMessage, recall bool) (int64, er
 	if err != nil {
 		return 0, err
 	}
+	queued := &queuedMessage{action: message.ActionGroupMessage, msg: dMsg}
+	if recall {
+		queued.action = message.ActionGroupMessageRecall
+		webhook.Publish(webhook.EventGroupRecall, msg)
+	} else {
+		webhook.Publish(webhook.EventGroupMessage, msg)
+	}
 
 	select {
-	case g.messages <- dMsg:
+	case g.messages <- queued:
 		atomic.AddInt32(&g.queued, 1)
 	default:
 		return 0, errors.New("too many messages,the group message queue is full")
@@ -177,15 +241,337 @@ func (g *Group) EnqueueMessage(msg *message.ChatMessage, recall bool) (int64, er
 		}
 		return 0, err
 	}
+	if !recall {
+		g.pushOffline(dMsg)
+	}
 	return seq, nil
 }
 
-func (g *Group) checkSeqRemain() {
-	g.seqRemain--
-	remain := atomic.AddInt64(&g.seqRemain, -1)
-	if remain <= 0 {
-		// TODO load a new segment
+// checkRecall 检查撤回权限, 发送者可以在撤回时限内撤回自己的消息,
+// 管理员可以在管理员撤回时限内撤回自己和普通成员的消息, 不能撤回其他管理员的消息
+func (g *Group) checkRecall(mf *memberInfo, from int64, r *message.Recall) error {
+	if r.RecallBy != from {
+		return errors.New("illegal operation")
+	}
+	origin, err := msgdao.GroupMsgDaoImpl.GetMessage(r.Mid)
+	if err != nil {
+		return err
+	}
+	if origin.To != g.gid || origin.Status == msgdao.ChatMessageStatusRecalled {
+		return errors.New("illegal operation")
+	}
+	elapsed := time.Now().Unix() - origin.SendAt
+	if origin.From == from && elapsed <= config.Messaging.GroupRecallWindow {
+		return nil
+	}
+	if !mf.admin {
+		return errors.New("message recall window expired")
+	}
+	if origin.From != from {
+		g.mu.Lock()
+		sender, ok := g.members[origin.From]
+		g.mu.Unlock()
+		if ok && sender.admin {
+			return errors.New("cannot recall message of another admin")
+		}
+	}
+	if w := config.Messaging.GroupAdminRecallWindow; w > 0 && elapsed > w {
+		return errors.New("message recall window expired")
+	}
+	return nil
+}
+
+// EditMessage 编辑群消息, 仅原发送者可以在编辑时限内编辑, 编辑后写入成员收件箱并向在线成员下发编辑事件
+func (g *Group) EditMessage(msg *message.ChatMessage) error {
+
+	g.mu.Lock()
+	mf, exist := g.members[msg.From]
+	g.mu.Unlock()
+
+	if !exist {
+		return errors.New("not a group member")
+	}
+	if mf.muted {
+		return errors.New("a muted group member edit message")
+	}
+
+	e := &message.Edit{}
+	err := message.DefaultCodec.Decode([]byte(msg.Content), e)
+	if err != nil {
+		return err
+	}
+	origin, err := msgdao.GroupMsgDaoImpl.GetMessage(e.Mid)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if origin.To != g.gid || origin.From != msg.From {
+		return errors.New("illegal operation")
+	}
+	createAt := origin.CreateAt
+	if createAt == 0 {
+		createAt = origin.SendAt
+	}
+	if now-createAt > config.Messaging.EditWindow {
+		return errors.New("message edit window expired")
+	}
+	e.EditAt = now
+	err = msgdao.GroupMsgDaoImpl.EditGroupMessage(g.gid, e.Mid, msg.From, e.Content, e.EditAt)
+	if err != nil {
+		return err
+	}
+	content, err := message.DefaultCodec.Encode(e)
+	if err != nil {
+		return err
+	}
+	msg.Content = string(content)
+	msg.To = g.gid
+	msg.SendAt = now
+	// 编辑事件写入所有成员的收件箱, 离线成员和编辑者的其他设备由收件箱同步
+	g.writeInbox(0, message.ActionGroupMessageEdit, msg.Mid, msg)
+	g.SendMessage(msg.From, message.NewMessage(-1, message.ActionGroupMessageEdit, msg))
+	webhook.Publish(webhook.EventGroupEdit, msg)
+	return nil
+}
+
+// ReactMessage 添加或移除群消息的表情回应, 并通知所有群成员
+func (g *Group) ReactMessage(msg *message.ChatMessage) error {
+
+	g.mu.Lock()
+	_, exist := g.members[msg.From]
+	g.mu.Unlock()
+
+	if !exist {
+		return errors.New("not a group member")
 	}
+
+	r := &message.Reaction{}
+	err := message.DefaultCodec.Decode([]byte(msg.Content), r)
+	if err != nil {
+		return err
+	}
+	origin, err := msgdao.GroupMsgDaoImpl.GetMessage(r.Mid)
+	if err != nil {
+		return err
+	}
+	if origin.To != g.gid || origin.Status == msgdao.ChatMessageStatusRecalled {
+		return errors.New("illegal operation")
+	}
+	if r.Remove {
+		err = msgdao.ReactionDaoImpl.RemoveReaction(r.Mid, msg.From, r.Emoji)
+	} else {
+		_, err = msgdao.ReactionDaoImpl.AddReaction(r.Mid, msg.From, r.Emoji)
+	}
+	if err != nil {
+		return err
+	}
+	r.From = msg.From
+	r.To = g.gid
+	g.SendMessage(msg.From, message.NewMessage(-1, message.ActionGroupMessageReaction, r))
+	return nil
+}
+
+// scheduleExpire 使用时间轮回调在消息过期时删除消息, 服务重启丢失的任务由定期清理任务处理
+func (g *Group) scheduleExpire(mid int64, from int64, ttl int64) {
+	tw.After(time.Duration(ttl) * time.Second).Callback(func() {
+		err := msgdao.ExpireDaoImpl.DeleteGroupMessages(mid)
+		if err != nil {
+			logger.E("Group.scheduleExpire delete expired message error, %v", err)
+			return
+		}
+		e := &message.Expired{}
+		e.Mid = mid
+		e.From = from
+		e.To = g.gid
+		g.SendMessage(0, message.NewMessage(-1, message.ActionGroupMessageExpired, e))
+	})
+}
+
+// NotifyExpired 通知在线成员消息已过期删除
+func (g *Group) NotifyExpired(msg *message.ChatMessage) error {
+	e := &message.Expired{}
+	err := message.JsonCodec.Decode([]byte(msg.Content), e)
+	if err != nil {
+		return err
+	}
+	e.To = g.gid
+	g.SendMessage(0, message.NewMessage(-1, message.ActionGroupMessageExpired, e))
+	return nil
+}
+
+// Typing 转发正在输入事件给其他在线成员, 人数较多的群不转发
+func (g *Group) Typing(msg *message.ChatMessage) error {
+	g.mu.Lock()
+	_, exist := g.members[msg.From]
+	size := len(g.members)
+	g.mu.Unlock()
+
+	if !exist {
+		return errors.New("not a group member")
+	}
+	if size > config.Messaging.TypingGroupMaxMembers {
+		return nil
+	}
+	t := &message.Typing{}
+	err := message.JsonCodec.Decode([]byte(msg.Content), t)
+	if err != nil {
+		return err
+	}
+	t.From = msg.From
+	t.To = g.gid
+	t.Group = true
+	g.SendMessage(msg.From, message.NewMessage(-1, message.ActionTyping, t))
+	return nil
+}
+
+// AckMessage 记录群成员的确认位置, 并计划向消息发送者推送已读人数变化
+func (g *Group) AckMessage(msg *message.ChatMessage) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	mf, exist := g.members[msg.From]
+	if !exist {
+		return errors.New("not a group member")
+	}
+	if msg.Seq <= mf.ackSeq {
+		return nil
+	}
+	from := mf.ackSeq + 1
+	if mf.ackSeq == 0 {
+		from = msg.Seq - config.Messaging.GroupReadNotifyRange + 1
+	}
+	mf.ackSeq = msg.Seq
+
+	if g.readFrom == 0 || from < g.readFrom {
+		g.readFrom = from
+	}
+	if !g.readNotifying {
+		g.readNotifying = true
+		t := tw.After(time.Duration(config.Messaging.GroupReadNotifyInterval) * time.Second)
+		go func() {
+			<-t.C
+			g.notifyReadCount()
+		}()
+	}
+	return nil
+}
+
+// notifyReadCount 合并计算已读人数发生变化的群消息, 推送给在线的消息发送者
+func (g *Group) notifyReadCount() {
+	g.mu.Lock()
+	from := g.readFrom
+	g.readFrom = 0
+	g.readNotifying = false
+	g.mu.Unlock()
+
+	to := atomic.LoadInt64(&g.msgSequence)
+	if min := to - config.Messaging.GroupReadNotifyRange + 1; from < min {
+		from = min
+	}
+	if from <= 0 {
+		from = 1
+	}
+	if from > to {
+		return
+	}
+	ms, err := msgdao.GroupMsgDaoImpl.GetGroupMessageSeqSpan(g.gid, from, to)
+	if err != nil || len(ms) == 0 {
+		return
+	}
+
+	var seq []int64
+	var senders []int64
+	reads := map[int64]*message.GroupRead{}
+	for _, m := range ms {
+		seq = append(seq, m.Seq)
+		if _, ok := reads[m.From]; !ok {
+			reads[m.From] = message.NewGroupRead(g.gid)
+			senders = append(senders, m.From)
+		}
+	}
+	counts, err := msgdao.GroupMsgDaoImpl.GetGroupMessageReadCount(g.gid, seq...)
+	if err != nil {
+		logger.E("Group.notifyReadCount get read count error, %v", err)
+		return
+	}
+	states, err := msgdao.GroupMsgDaoImpl.GetGroupMemberMsgStates(g.gid, senders...)
+	if err != nil {
+		logger.E("Group.notifyReadCount get member state error, %v", err)
+		return
+	}
+	senderAck := map[int64]int64{}
+	for _, s := range states {
+		senderAck[s.UID] = s.LastAckSeq
+	}
+
+	for _, m := range ms {
+		count := counts[m.Seq]
+		// 发送者自己不计入已读人数
+		if senderAck[m.From] >= m.Seq && count > 0 {
+			count--
+		}
+		reads[m.From].AddRead(m.MID, m.Seq, count)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for uid, r := range reads {
+		mf, ok := g.members[uid]
+		if !ok || !mf.online {
+			continue
+		}
+		err = enqueueMessage(uid, 0, message.NewMessage(-1, message.ActionGroupMessageRead, r))
+		if err != nil {
+			logger.E("%v", err)
+		}
+	}
+}
+
+// loadSeq 从数据库中已租出的 seq 恢复, 第一次分配时租用新号段, 重启或迁移节点后 seq 仍然递增
+func (g *Group) loadSeq() error {
+	g.seqMu.Lock()
+	defer g.seqMu.Unlock()
+	return g.loadSeqLocked()
+}
+
+func (g *Group) loadSeqLocked() error {
+	if g.seqLoaded {
+		return nil
+	}
+	seq, err := msgdao.InitGroupMsgSeq(g.gid, msgSeqSegmentLen)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt64(&g.msgSequence, seq)
+	g.seqMax = seq
+	g.seqLoaded = true
+	return nil
+}
+
+// nextSeq 分配下一个消息 seq, 当前号段用尽时租用新号段, 未使用完的号段在重启后丢弃, seq 可能不连续
+func (g *Group) nextSeq() (int64, error) {
+	g.seqMu.Lock()
+	defer g.seqMu.Unlock()
+
+	if err := g.loadSeqLocked(); err != nil {
+		return 0, err
+	}
+	if g.msgSequence >= g.seqMax {
+		max, step, err := msgdao.LeaseGroupMsgSeq(g.gid, msgSeqSegmentLen)
+		if err != nil {
+			return 0, err
+		}
+		if step <= 0 {
+			step = msgSeqSegmentLen
+		}
+		// 数据库中的 seq 被回退时拒绝分配, 避免与已分配的 seq 重复
+		if max-step < g.msgSequence {
+			return 0, errors.New("leased group message seq segment is behind allocated seq")
+		}
+		atomic.StoreInt64(&g.msgSequence, max-step)
+		g.seqMax = max
+	}
+	return atomic.AddInt64(&g.msgSequence, 1), nil
 }
 
 func (g *Group) checkMsgQueue() error {
@@ -202,6 +588,7 @@ func (g *Group) checkMsgQueue() error {
 				case m := <-g.notify:
 					g.lastMsgAt = time.Now()
 					atomic.AddInt32(&g.queued, -1)
+					g.writeInbox(0, message.ActionNotifyGroup, m.Mid, m)
 					switch m.Type {
 					default:
 						g.SendMessage(0, message.NewMessage(0, message.ActionNotifyGroup, m))
@@ -221,10 +608,14 @@ func (g *Group) checkMsgQueue() error {
 					} else {
 						g.checkActive = tw.After(messageQueueSleep)
 					}
-				case m := <-g.messages:
+				case q := <-g.messages:
 					atomic.AddInt32(&g.queued, -1)
 					g.lastMsgAt = time.Now()
+					m := q.msg
+					// 发送者的收件箱也写入, 以便发送者的其他设备同步
+					g.writeInbox(0, q.action, m.Mid, &msgdao.InboxGroupPointer{Gid: g.gid, Mid: m.Mid, Seq: m.Seq})
 					g.SendMessage(m.From, message.NewMessage(-1, message.ActionGroupMessage, m))
+					g.dispatchBots(m)
 				}
 			}
 		REST:
@@ -238,6 +629,28 @@ func (g *Group) checkMsgQueue() error {
 	return err
 }
 
+// writeInbox 将群事件异步写入除 from 以外所有成员的收件箱
+func (g *Group) writeInbox(from int64, action message.Action, mid int64, data interface{}) {
+	g.mu.Lock()
+	var uids []int64
+	for uid, mf := range g.members {
+		if uid != from && !mf.bot {
+			uids = append(uids, uid)
+		}
+	}
+	g.mu.Unlock()
+	if len(uids) == 0 {
+		return
+	}
+	submitInbox(&inboxTask{
+		action: string(action),
+		mid:    mid,
+		gid:    g.gid,
+		data:   data,
+		uid:    uids,
+	})
+}
+
 func (g *Group) SendMessage(from int64, message *message.Message) {
 	logger.D("Group.SendMessage: %s", message)
 	g.mu.Lock()
@@ -253,10 +666,82 @@ func (g *Group) SendMessage(from int64, message *message.Message) {
 	g.mu.Unlock()
 }
 
+// dispatchBots 将群消息投递给群内除发送者以外的机器人
+func (g *Group) dispatchBots(m *message.ChatMessage) {
+	g.mu.Lock()
+	bots := map[int64]bool{}
+	for uid, mf := range g.members {
+		if mf.bot && uid != m.From {
+			bots[uid] = mf.botReadAll
+		}
+	}
+	g.mu.Unlock()
+	for uid, readAll := range bots {
+		bot.DeliverGroup(uid, readAll, m)
+	}
+}
+
+// pushOffline 向不在线的群成员推送离线通知, 在线状态的查询和推送在推送协程池中执行, 不阻塞消息队列
+func (g *Group) pushOffline(m *message.ChatMessage) {
+	if !push.Enabled() {
+		return
+	}
+	g.mu.Lock()
+	uids := make([]int64, 0, len(g.members))
+	for uid, mf := range g.members {
+		if uid != m.From && !mf.bot {
+			uids = append(uids, uid)
+		}
+	}
+	g.mu.Unlock()
+	if len(uids) == 0 {
+		return
+	}
+	err := pushExec.Submit(func() {
+		// 每条消息只查询一次所有成员的在线状态
+		online := client.OnlineUsers(uids...)
+		for _, uid := range uids {
+			if online[uid] {
+				continue
+			}
+			push.Notify(&push.Message{
+				Uid:     uid,
+				From:    m.From,
+				Gid:     g.gid,
+				Mid:     m.Mid,
+				Type:    m.Type,
+				Content: m.Content,
+			})
+		}
+	})
+	if err != nil {
+		logger.E("submit group offline push error %v", err)
+	}
+}
+
 func (g *Group) updateMember(u MemberUpdate) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	mf, ok := g.members[u.Uid]
+	if u.Flag&(FlagMemberBot|FlagMemberBotRemoved) != 0 {
+		// 机器人标记只能用于机器人账号, 避免把普通成员变为机器人或者移出
+		if uid.IsUserId(u.Uid) || (ok && !mf.bot) {
+			return errors.New("member is not a bot")
+		}
+	}
+	if u.Flag&FlagMemberBotRemoved != 0 {
+		delete(g.members, u.Uid)
+		return nil
+	}
+	if u.Flag&FlagMemberBot != 0 {
+		if !ok {
+			mf = newMemberInfo()
+			g.members[u.Uid] = mf
+		}
+		mf.bot = true
+		mf.botReadAll = u.Flag&FlagMemberBotReadAll != 0
+		return nil
+	}
 	if !ok && u.Flag&FlagMemberOnline != 1 {
 		return errors.New("member not exist")
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/glide-im/glideim/pkg/logger"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -34,8 +35,12 @@ func (m *DefaultManager) Init() {
 	}
 	for _, g := range groups {
 		logger.D("load group %d", g.Gid)
-		// TODO load seq segment and seq
-		sGroup := newGroup(g.Gid, 0)
+		sGroup := newGroup(g.Gid)
+		if err = sGroup.loadSeq(); err != nil {
+			// 加载失败的群在分配 seq 时重试, 群仍然加载, 避免重启前一直无法发送消息
+			logger.E("load group message seq error gid=%d %v", g.Gid, err)
+		}
+		sGroup.msgTTL = g.MsgTTL
 		m.groups[g.Gid] = sGroup
 		mbs, err := groupdao.Dao.GetMembers(g.Gid)
 		if err != nil {
@@ -44,9 +49,14 @@ func (m *DefaultManager) Init() {
 		}
 		for _, mb := range mbs {
 			info := newMemberInfo()
-			info.muted = mb.Flag == 1
+			info.muted = mb.Flag&groupdao.GroupMemberFlagMuted != 0
 			info.admin = mb.Type == 1
 			info.online = true
+			if mb.Type == groupdao.GroupMemberTypeBot {
+				info.online = false
+				info.bot = true
+				info.botReadAll = mb.Flag&groupdao.GroupMemberFlagBotReadAll != 0
+			}
 			sGroup.PutMember(mb.Uid, info)
 		}
 	}
@@ -71,8 +81,12 @@ func (m *DefaultManager) UpdateMember(gid int64, update []MemberUpdate) error {
 func (m *DefaultManager) UpdateGroup(gid int64, update Update) error {
 
 	if update.Flag == FlagGroupCreate {
+		g := newGroup(gid)
+		if err := g.loadSeq(); err != nil {
+			logger.E("load group message seq error gid=%d %v", gid, err)
+		}
 		m.mu.Lock()
-		m.groups[gid] = newGroup(gid, 0)
+		m.groups[gid] = g
 		m.mu.Unlock()
 		return nil
 	}
@@ -87,6 +101,12 @@ func (m *DefaultManager) UpdateGroup(gid int64, update Update) error {
 		g.mute = true
 	case FlagGroupCancelMute:
 		g.mute = false
+	case FlagGroupMsgTTL:
+		ttl, ok := update.Extra.(int64)
+		if !ok {
+			return errors.New("invalid group message ttl")
+		}
+		atomic.StoreInt64(&g.msgTTL, ttl)
 	case FlagGroupDissolve:
 		g.dissolved = true
 		tw.After(time.Second * 10).Callback(func() {
@@ -113,19 +133,40 @@ func (m *DefaultManager) DispatchMessage(gid int64, action message.Action, msg *
 	if !ok {
 		return errors.New("group not exist gid=" + strconv.FormatInt(gid, 10))
 	}
+	switch action {
+	case message.ActionAckGroupMsg:
+		// 成员确认消息不受禁言影响, 也不需要 ack
+		return g.AckMessage(msg)
+	case message.ActionGroupMessageExpired:
+		return g.NotifyExpired(msg)
+	}
 	if g.mute && action != message.ActionGroupMessageRecall {
 		return errors.New("group is muted")
 	}
 	if g.dissolved {
 		return errors.New("group is dissolved")
 	}
-	seq, err := g.EnqueueMessage(msg, action == message.ActionGroupMessageRecall)
+	var seq int64
+	var err error
+	// 入队后 msg.Seq 为群消息 seq, 先保存客户端 seq 用于确认
+	cliSeq := msg.Seq
+	switch action {
+	case message.ActionGroupMessageEdit:
+		err = g.EditMessage(msg)
+	case message.ActionTyping:
+		return g.Typing(msg)
+	case message.ActionGroupMessageReaction:
+		// 表情回应不需要 ack
+		return g.ReactMessage(msg)
+	default:
+		seq, err = g.EnqueueMessage(msg, action == message.ActionGroupMessageRecall)
+	}
 
 	if err != nil {
 		return err
 	} else {
 		// notify sender, group message send successful
-		ack := message.NewMessage(0, message.ActionAckNotify, message.NewAckMessage(msg.Mid, seq))
+		ack := message.NewMessage(cliSeq, message.ActionAckNotify, message.NewAckMessage(msg.Mid, seq))
 		client.EnqueueMessage(msg.From, ack)
 	}
 
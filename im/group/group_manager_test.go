@@ -2,6 +2,7 @@ package group
 
 import (
 	"github.com/glide-im/glideim/im/dao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/message"
 	"github.com/glide-im/glideim/pkg/db"
 	"github.com/glide-im/glideim/pkg/logger"
@@ -13,6 +14,7 @@ import (
 var dispatchCount int32 = 0
 
 func initDepMock() {
+	msgdao.MockGroupMsgSeq(0)
 	enqueueMessage = func(uid int64, device int64, message *message.Message) error {
 		logger.D("%d, %d, %s", uid, device, message.GetData())
 		atomic.AddInt32(&dispatchCount, 1)
@@ -0,0 +1,54 @@
+package group
+
+import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"testing"
+)
+
+func TestGroup_nextSeq(t *testing.T) {
+	msgdao.MockGroupMsgSeq(0)
+
+	g := newGroup(100)
+	var last int64
+	for i := 0; i < msgSeqSegmentLen*2+1; i++ {
+		seq, err := g.nextSeq()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq <= last {
+			t.Fatalf("seq not increasing, last=%d, seq=%d", last, seq)
+		}
+		last = seq
+	}
+
+	// 重新加载的群从已租出的最大 seq 之后继续分配
+	g = newGroup(100)
+	seq, err := g.nextSeq()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != msgSeqSegmentLen*3+1 {
+		t.Errorf("expect seq %d after reload, got %d", msgSeqSegmentLen*3+1, seq)
+	}
+}
+
+func TestGroup_updateMemberBot(t *testing.T) {
+	g := newGroup(100)
+	g.PutMember(1001, newMemberInfo())
+
+	if err := g.updateMember(MemberUpdate{Uid: 543602, Flag: FlagMemberBot}); err == nil {
+		t.Error("expect user uid rejected as bot")
+	}
+	if err := g.updateMember(MemberUpdate{Uid: 1001, Flag: FlagMemberBot}); err == nil {
+		t.Error("expect existing member rejected as bot")
+	}
+	if err := g.updateMember(MemberUpdate{Uid: 1001, Flag: FlagMemberBotRemoved}); err == nil || g.GetMember(1001) == nil {
+		t.Error("expect existing member not removed as bot")
+	}
+	if err := g.updateMember(MemberUpdate{Uid: 1002, Flag: FlagMemberBot | FlagMemberBotReadAll}); err != nil {
+		t.Fatal(err)
+	}
+	if m := g.GetMember(1002); m == nil || !m.bot || !m.botReadAll {
+		t.Error("expect bot member added")
+	}
+}
@@ -0,0 +1,66 @@
+package group
+
+import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/pkg/logger"
+	"sync"
+)
+
+const (
+	// inboxWorkers 写收件箱的协程数, 同一个群的事件由同一个协程按顺序写入
+	inboxWorkers = 16
+	// inboxQueueSize 每个协程的等待队列长度, 队列满时在调用者中直接写入
+	inboxQueueSize = 1024
+	// inboxBatchSize 每个事务最多写入的成员数, 大群分批写入, 避免长事务锁住大量成员的收件箱 Seq
+	inboxBatchSize = 200
+)
+
+type inboxTask struct {
+	action string
+	mid    int64
+	gid    int64
+	data   interface{}
+	uid    []int64
+}
+
+var (
+	inboxQueues [inboxWorkers]chan *inboxTask
+	inboxOnce   sync.Once
+)
+
+func startInboxWorkers() {
+	for i := range inboxQueues {
+		q := make(chan *inboxTask, inboxQueueSize)
+		inboxQueues[i] = q
+		go func() {
+			for t := range q {
+				writeInboxTask(t)
+			}
+		}()
+	}
+}
+
+// submitInbox 将群事件交给写收件箱的协程异步写入, 不阻塞群消息队列
+func submitInbox(t *inboxTask) {
+	inboxOnce.Do(startInboxWorkers)
+	q := inboxQueues[uint64(t.gid)%inboxWorkers]
+	select {
+	case q <- t:
+	default:
+		logger.W("group inbox queue is full, gid=%d", t.gid)
+		writeInboxTask(t)
+	}
+}
+
+func writeInboxTask(t *inboxTask) {
+	for start := 0; start < len(t.uid); start += inboxBatchSize {
+		end := start + inboxBatchSize
+		if end > len(t.uid) {
+			end = len(t.uid)
+		}
+		err := msgdao.AddInboxEvent(t.action, t.mid, t.gid, t.data, t.uid[start:end]...)
+		if err != nil {
+			logger.E("Group.writeInbox error, %v", err)
+		}
+	}
+}
@@ -11,6 +11,10 @@ const (
 	FlagMemberMuted             = 1 << 1
 	FlagMemberTypeAdmin         = 1 << 2
 	FlagMemberTypeGeneral       = 1 << 3
+	// FlagMemberBot 添加或更新机器人成员, 与 FlagMemberBotReadAll 一起使用时机器人可以收到所有消息
+	FlagMemberBot        = 1 << 4
+	FlagMemberBotReadAll = 1 << 5
+	FlagMemberBotRemoved = 1 << 6
 )
 
 const (
@@ -18,6 +22,7 @@ const (
 	FlagGroupDissolve         = 2
 	FlagGroupMute             = 3
 	FlagGroupCancelMute       = 4
+	FlagGroupMsgTTL           = 5
 )
 
 type MessageHandler func(uid int64, device int64, message *message.Message) error
@@ -84,3 +89,28 @@ func DispatchMessage(gid int64, msg *message.ChatMessage) error {
 func DispatchRecallMessage(gid int64, msg *message.ChatMessage) error {
 	return manager.DispatchMessage(gid, message.ActionGroupMessageRecall, msg)
 }
+
+// DispatchEditMessage 发送编辑群消息
+func DispatchEditMessage(gid int64, msg *message.ChatMessage) error {
+	return manager.DispatchMessage(gid, message.ActionGroupMessageEdit, msg)
+}
+
+// DispatchReactionMessage 发送群消息表情回应
+func DispatchReactionMessage(gid int64, msg *message.ChatMessage) error {
+	return manager.DispatchMessage(gid, message.ActionGroupMessageReaction, msg)
+}
+
+// DispatchExpiredMessage 通知群成员消息已过期删除
+func DispatchExpiredMessage(gid int64, msg *message.ChatMessage) error {
+	return manager.DispatchMessage(gid, message.ActionGroupMessageExpired, msg)
+}
+
+// DispatchTypingMessage 转发正在输入事件给群成员
+func DispatchTypingMessage(gid int64, msg *message.ChatMessage) error {
+	return manager.DispatchMessage(gid, message.ActionTyping, msg)
+}
+
+// DispatchAckMessage 群成员确认收到消息, 用于计算群消息已读人数
+func DispatchAckMessage(gid int64, msg *message.ChatMessage) error {
+	return manager.DispatchMessage(gid, message.ActionAckGroupMsg, msg)
+}
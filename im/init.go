@@ -4,12 +4,25 @@ import (
 	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/dao"
 	"github.com/glide-im/glideim/im/messaging"
+	"github.com/glide-im/glideim/im/moderation"
+	"github.com/glide-im/glideim/im/push"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/db"
 )
 
 func Init() {
 	db.Init()
 	dao.Init()
+	if err := moderation.Init(); err != nil {
+		panic(err)
+	}
+	if err := webhook.Init(); err != nil {
+		panic(err)
+	}
+	go webhook.Run()
+	if err := push.Init(); err != nil {
+		panic(err)
+	}
 
 	client.SetMessageHandler(messaging.HandleMessage)
 }
@@ -3,16 +3,32 @@ package message
 type Action string
 
 const (
-	ActionMessage            Action = "message"
-	ActionChatMessage               = "message.chat"
-	ActionChatMessageRecall         = "message.chat.recall"
-	ActionChatMessageRetry          = "message.chat.retry"  // 消息重发, 服务器未ack
-	ActionChatMessageResend         = "message.chat.resend" // 消息重发, 服务器已ack, 接收方未ack
-	ActionGroupMessage              = "message.group"
-	ActionGroupMessageRecall        = "message.group.recall"
-	ActionCSMessage                 = "message.cs"
-	ActionMessageFailed             = "message.failed.send"
-	ActionClientCustom              = "message.cli"
+	ActionMessage              Action = "message"
+	ActionChatMessage                 = "message.chat"
+	ActionChatMessageRecall           = "message.chat.recall"
+	ActionChatMessageEdit             = "message.chat.edit"
+	ActionChatMessageReaction         = "message.chat.reaction"
+	ActionChatMessageRead             = "message.chat.read"
+	ActionChatMessageExpired          = "message.chat.expired"
+	ActionChatMessageRetry            = "message.chat.retry"  // 消息重发, 服务器未ack
+	ActionChatMessageResend           = "message.chat.resend" // 消息重发, 服务器已ack, 接收方未ack
+	ActionGroupMessage                = "message.group"
+	ActionGroupMessageRecall          = "message.group.recall"
+	ActionGroupMessageEdit            = "message.group.edit"
+	ActionGroupMessageReaction        = "message.group.reaction"
+	ActionGroupMessageRead            = "message.group.read"
+	ActionGroupMessageExpired         = "message.group.expired"
+	ActionGroupMessageSummary         = "message.group.summary"
+	ActionCSMessage                   = "message.cs"
+	ActionMessageFailed               = "message.failed.send"
+	ActionClientCustom                = "message.cli"
+	ActionMessageForward              = "message.forward"
+	ActionTyping                      = "message.typing"
+	ActionMessageSentSync             = "message.sent"
+	ActionMessageDelete               = "message.delete"
+	ActionMessageClear                = "message.clear"
+	ActionMessageRejected             = "message.rejected"
+	ActionSessionTTL                  = "message.session.ttl"
 
 	ActionNotifyNeedAuth      = "notify.auth"
 	ActionNotifyKickOut       = "notify.kickout"
@@ -22,3 +22,121 @@ type AckGroupMessage struct {
 type Recall struct {
 	pb_im.Recall
 }
+
+// Edit 编辑消息内容, 编码后放在 ChatMessage.Content 中
+type Edit struct {
+	json.Edit
+}
+
+// Reaction 消息表情回应
+type Reaction struct {
+	json.Reaction
+}
+
+// Read 单聊已读回执
+type Read struct {
+	json.Read
+}
+
+// MessageTypeChatRecord 合并转发的聊天记录消息类型, 消息内容为 ChatRecord 的 json
+const MessageTypeChatRecord int32 = 100
+
+// Forward 转发消息
+type Forward struct {
+	json.Forward
+}
+
+// ForwardResult 转发结果
+type ForwardResult struct {
+	json.ForwardResult
+}
+
+// ChatRecord 合并转发的聊天记录
+type ChatRecord struct {
+	json.ChatRecord
+}
+
+// AddMessage 添加一条消息到聊天记录
+func (c *ChatRecord) AddMessage(mid int64, from int64, typ int32, content string, sendAt int64) {
+	c.Messages = append(c.Messages, &json.ChatRecordItem{
+		Mid:     mid,
+		From:    from,
+		Type:    typ,
+		Content: content,
+		SendAt:  sendAt,
+	})
+}
+
+// Expired 消息过期删除事件
+type Expired struct {
+	json.Expired
+}
+
+// SessionTTL 会话消息存活时间修改事件
+type SessionTTL struct {
+	json.SessionTTL
+}
+
+// Typing 正在输入事件
+type Typing struct {
+	json.Typing
+}
+
+// GroupRead 群消息已读人数变化
+type GroupRead struct {
+	json.GroupRead
+}
+
+// GroupSummary 登录时错过的群消息未读摘要
+type GroupSummary struct {
+	json.GroupSummary
+}
+
+// Delete 仅为自己删除消息
+type Delete struct {
+	json.Delete
+}
+
+// ClearHistory 清空聊天记录
+type ClearHistory struct {
+	json.ClearHistory
+}
+
+// SentSync 发出的消息同步给发送者的其他设备
+type SentSync struct {
+	json.SentSync
+}
+
+// Rejected 消息未通过内容审核
+type Rejected struct {
+	json.Rejected
+}
+
+func NewRejected(to int64, group bool, reason string) *Rejected {
+	return &Rejected{json.Rejected{To: to, Group: group, Reason: reason}}
+}
+
+func NewSentSync(action Action, msg interface{}) *SentSync {
+	return &SentSync{json.SentSync{Action: string(action), SentByMe: true, Message: msg}}
+}
+
+func NewGroupSummary(gid int64, ackSeq int64, lastSeq int64, lastMid int64, lastMsgAt int64) *GroupSummary {
+	return &GroupSummary{json.GroupSummary{
+		Gid:       gid,
+		AckSeq:    ackSeq,
+		LastSeq:   lastSeq,
+		LastMid:   lastMid,
+		LastMsgAt: lastMsgAt,
+		Unread:    lastSeq - ackSeq,
+	}}
+}
+
+func NewGroupRead(gid int64) *GroupRead {
+	//goland:noinspection GoPreferNilSlice
+	return &GroupRead{json.GroupRead{Gid: gid, Reads: []*json.GroupReadCount{}}}
+}
+
+// AddRead 添加一条群消息的已读人数
+func (g *GroupRead) AddRead(mid int64, seq int64, count int64) {
+	g.Reads = append(g.Reads, &json.GroupReadCount{Mid: mid, Seq: seq, Count: count})
+}
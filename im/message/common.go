@@ -129,6 +129,19 @@ func NewMessage(seq int64, action Action, data interface{}) *Message {
 	return &message
 }
 
+// NewJsonMessage 创建数据已编码为 json 的消息, 与从客户端收到的 json 消息一致, 用于服务端构造上行消息
+func NewJsonMessage(seq int64, action Action, data interface{}) (*Message, error) {
+	b, err := JsonCodec.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		pb:   nil,
+		json: json.NewMessage(seq, string(action), b),
+		data: nil,
+	}, nil
+}
+
 func NewEmptyMessage() *Message {
 	return &Message{
 		pb:   nil,
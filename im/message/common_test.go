@@ -40,3 +40,19 @@ func TestJsonCodec_Decode(t *testing.T) {
 
 	t.Log(cm)
 }
+
+func TestNewJsonMessage(t *testing.T) {
+	c := NewChatMessage(1, 2, 3, 4, 1, "hello", 5)
+	m, err := NewJsonMessage(1, ActionChatMessage, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := ChatMessage{}
+	err = m.DeserializeData(&cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Mid != 1 || cm.From != 3 || cm.To != 4 || cm.Content != "hello" {
+		t.Errorf("unexpected message %v", cm)
+	}
+}
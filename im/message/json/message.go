@@ -77,6 +77,166 @@ type Recall struct {
 	Mid      int64
 }
 
+// Edit 编辑消息, 由 ChatMessage.Content 携带
+type Edit struct {
+	// Mid 被编辑的消息 ID
+	Mid int64
+	// Content 编辑后的内容
+	Content string
+	// EditAt 编辑时间, 由服务端填写
+	EditAt int64
+}
+
+// Reaction 对消息添加或移除表情回应
+type Reaction struct {
+	// Mid 回应的消息 ID
+	Mid int64
+	// From 回应者, 由服务端填写
+	From int64
+	// To 单聊为对方 ID, 群聊为群 ID
+	To int64
+	// Emoji 表情
+	Emoji string
+	// Remove 为 true 时表示移除该表情回应
+	Remove bool
+}
+
+// Read 单聊已读回执
+type Read struct {
+	// Mid 已读的最后一条消息 ID, 为 0 时表示读到会话最后一条消息
+	Mid int64
+	// From 已读者, 由服务端填写
+	From int64
+	// To 会话的另一方
+	To int64
+	// ReadAt 已读时间, 由服务端填写
+	ReadAt int64
+}
+
+// GroupReadCount 群消息已读人数
+type GroupReadCount struct {
+	Mid   int64
+	Seq   int64
+	Count int64
+}
+
+// GroupRead 群消息已读人数变化, 推送给消息发送者
+type GroupRead struct {
+	Gid   int64
+	Reads []*GroupReadCount
+}
+
+// GroupSummary 登录时错过的群消息过多, 只推送未读摘要, 客户端按需拉取历史消息
+type GroupSummary struct {
+	Gid int64
+	// AckSeq 用户最后确认收到的消息 seq
+	AckSeq int64
+	// LastSeq, LastMid, LastMsgAt 群最新一条消息
+	LastSeq   int64
+	LastMid   int64
+	LastMsgAt int64
+	// Unread 错过的消息数量
+	Unread int64
+}
+
+// Typing 正在输入事件, 只转发给在线的对方, 不保存
+type Typing struct {
+	// From 输入者, 由服务端填写
+	From int64
+	// To 单聊为对方 ID, 群聊为群 ID
+	To int64
+	// Group 是否为群聊
+	Group bool
+	// Stop 为 true 时表示停止输入
+	Stop bool
+}
+
+// Expired 消息已过期并被删除
+type Expired struct {
+	Mid  int64
+	From int64
+	// To 单聊为接收者 ID, 群聊为群 ID
+	To int64
+}
+
+// SessionTTL 单聊会话的消息存活时间被修改, 通知会话双方
+type SessionTTL struct {
+	// From 修改者
+	From int64
+	// To 会话的另一方
+	To int64
+	// TTL 消息存活时间, 单位秒, 0 表示消息不过期
+	TTL int64
+}
+
+// Forward 转发消息, 服务端根据源消息 ID 复制内容
+type Forward struct {
+	// Mid 源消息 ID
+	Mid []int64
+	// FromGroup 源消息是否为群消息
+	FromGroup bool
+	// To 目标, 单聊为接收者 ID, 群聊为群 ID
+	To int64
+	// ToGroup 目标是否为群
+	ToGroup bool
+	// Merge 为 true 时将多条消息合并为一条聊天记录消息
+	Merge bool
+}
+
+// ForwardResult 转发结果, 返回转发生成的消息 ID
+type ForwardResult struct {
+	Mid []int64
+}
+
+// ChatRecord 合并转发的聊天记录
+type ChatRecord struct {
+	Messages []*ChatRecordItem
+}
+
+type ChatRecordItem struct {
+	Mid     int64
+	From    int64
+	Type    int32
+	Content string
+	SendAt  int64
+}
+
+// Delete 用户仅为自己删除消息, 同步给该用户的所有设备
+type Delete struct {
+	Mid   []int64
+	Group bool
+}
+
+// ClearHistory 用户清空单聊或群的聊天记录, 同步给该用户的所有设备
+type ClearHistory struct {
+	// To 单聊为对方 ID, 群聊为群 ID
+	To    int64
+	Group bool
+	// BeforeMid 该消息及之前的消息被清空
+	BeforeMid int64
+	// BeforeSeq 群聊中该 seq 及之前的消息被清空, 单聊为 0
+	BeforeSeq int64
+}
+
+// SentSync 用户在一台设备上发出的消息, 同步给该用户的其他设备
+type SentSync struct {
+	// Action 原消息的动作, 如单聊消息, 群消息, 撤回, 编辑
+	Action string
+	// SentByMe 消息由当前用户发出, 始终为 true
+	SentByMe bool
+	// Message 原消息数据
+	Message interface{}
+}
+
+// Rejected 消息未通过内容审核, 拒绝发送
+type Rejected struct {
+	// To 单聊为接收者 ID, 群聊为群 ID
+	To    int64
+	Group bool
+	// Reason 拒绝原因
+	Reason string
+}
+
 type ClientCustomMessage struct {
 	From    int64
 	To      int64
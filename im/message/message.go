@@ -74,3 +74,14 @@ func NewGroupNotify(mid, gid int64, seq int64, typ int64, timestamp int64, data
 type ClientCustom struct {
 	*json.ClientCustomMessage
 }
+
+func NewClientCustom(from, to int64, typ int32, content string) *ClientCustom {
+	return &ClientCustom{
+		&json.ClientCustomMessage{
+			From:    from,
+			To:      to,
+			Type:    typ,
+			Content: content,
+		},
+	}
+}
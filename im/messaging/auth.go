@@ -21,6 +21,7 @@ func handleAuth(from int64, device int64, msg *message.Message) {
 		resp := message.NewMessage(msg.GetSeq(), message.ActionApiSuccess, result)
 		_ = client.SignIn(from, result.Uid, device)
 		_ = client.EnqueueMessageToDevice(result.Uid, device, resp)
+		go syncGroupMessages(result.Uid, device)
 	} else {
 		resp := message.NewMessage(0, message.ActionApiFailed, err.Error())
 		_ = client.EnqueueMessageToDevice(from, device, resp)
@@ -1,15 +1,21 @@
 package messaging
 
 import (
+	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/bot"
 	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/dao/uid"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/push"
+	"github.com/glide-im/glideim/im/webhook"
 	"github.com/glide-im/glideim/pkg/logger"
 	"strconv"
+	"time"
 )
 
-// handleChatMessage 分发用户单聊消息
+// handleChatMessage 分发用户单聊消息, 新消息和撤回事件的 mid 由服务端分配, 确认消息以客户端 seq 对应服务端 mid
 func handleChatMessage(from int64, device int64, m *message.Message) {
 	if uid.IsTempId(from) {
 		logger.D("not sign in")
@@ -22,47 +28,180 @@ func handleChatMessage(from int64, device int64, m *message.Message) {
 	}
 	msg.From = from
 
-	if m.GetAction() != message.ActionChatMessageResend {
-		lg := from
-		sm := msg.To
-		if lg < sm {
-			lg, sm = sm, lg
+	switch m.GetAction() {
+	case message.ActionChatMessageResend:
+		if err := loadResendMessage(from, msg); err != nil {
+			logger.E("resend chat message error %v", err)
+			notifyMessageFailed(from, msg.Mid)
+			return
 		}
-		sessionId := strconv.FormatInt(lg, 10) + "_" + strconv.FormatInt(sm, 10)
-		if m.GetAction() == message.ActionChatMessageRecall {
-			r := &message.Recall{}
-			err := message.DefaultCodec.Decode([]byte(msg.Content), r)
-			if err != nil || r.RecallBy != from {
-				return
-			}
-			err = msgdao.ChatMsgDaoImpl.UpdateChatMessageStatus(r.Mid, r.RecallBy, msg.To, msgdao.ChatMessageStatusRecalled)
-			if err != nil {
-				logger.E("update message status error %v", err)
-				return
-			}
-		} else {
-			dbMsg := msgdao.ChatMessage{
-				MID:       msg.Mid,
-				From:      from,
-				To:        msg.To,
-				Type:      msg.Type,
-				SendAt:    msg.SendAt,
-				Content:   msg.Content,
-				CliSeq:    msg.Seq,
-				SessionID: sessionId,
-			}
-			// 保存消息
-			_, err := msgdao.AddChatMessage(&dbMsg)
-			if err != nil {
-				logger.E("save chat message error %v", err)
-				return
+	case message.ActionChatMessageRecall:
+		r := &message.Recall{}
+		err := message.DefaultCodec.Decode([]byte(msg.Content), r)
+		if err != nil || r.RecallBy != from {
+			return
+		}
+		if err = recallChatMessage(from, msg.To, r.Mid); err != nil {
+			logger.E("recall chat message error %v", err)
+			notifyMessageFailed(from, msg.Mid)
+			return
+		}
+		msg.Mid, err = msgdao.GetMessageID()
+		if err != nil {
+			logger.E("get message id error %v", err)
+			notifyMessageFailed(from, r.Mid)
+			return
+		}
+	default:
+		// 客户端重复发送, 已处理完成的重新确认原消息, 不再保存和投递
+		claimed, mid := claimSend(from, device, msg.Seq)
+		if !claimed {
+			if mid != 0 {
+				ackChatMessage(from, device, msg.Seq, mid)
 			}
+			return
 		}
+		r, ok := moderateMessage(from, device, msg.Seq, msg.To, false, msg.Type, &msg.Content)
+		if !ok {
+			releaseSend(from, device, msg.Seq)
+			return
+		}
+		var err error
+		msg.Mid, err = msgdao.GetMessageID()
+		if err == nil {
+			err = saveChatMessage(msg)
+		}
+		if err != nil {
+			logger.E("save chat message error %v", err)
+			releaseSend(from, device, msg.Seq)
+			notifyMessageFailed(from, msg.Mid)
+			return
+		}
+		completeSend(from, device, msg.Seq, msg.Mid)
+		flagForReview(msg.Mid, from, msg.To, false, msg.Content, r)
 	}
 
 	// 告诉客户端服务端已收到
-	ackChatMessage(from, device, msg.Mid)
+	ackChatMessage(from, device, msg.Seq, msg.Mid)
+	deliverChatMessage(from, device, m, msg)
+}
+
+// saveChatMessage 保存单聊消息并更新会话, 会话设置了消息有效期时记录过期时间
+func saveChatMessage(msg *message.ChatMessage) error {
+	lg := msg.From
+	sm := msg.To
+	if lg < sm {
+		lg, sm = sm, lg
+	}
+	sessionId := strconv.FormatInt(lg, 10) + "_" + strconv.FormatInt(sm, 10)
+	ttl, err := msgdao.SessionDaoImpl.GetSessionTTL(msg.From, msg.To)
+	if err != nil {
+		logger.E("get session ttl error %v", err)
+	}
+	dbMsg := msgdao.ChatMessage{
+		MID:       msg.Mid,
+		From:      msg.From,
+		To:        msg.To,
+		Type:      msg.Type,
+		SendAt:    msg.SendAt,
+		CreateAt:  time.Now().Unix(),
+		Content:   msg.Content,
+		CliSeq:    msg.Seq,
+		SessionID: sessionId,
+	}
+	if ttl > 0 {
+		dbMsg.ExpireAt = dbMsg.CreateAt + ttl
+	}
+	// 保存消息
+	_, err = msgdao.AddChatMessage(&dbMsg)
+	if err != nil {
+		return err
+	}
+	if ttl > 0 && ttl <= config.Messaging.ExpireNearTerm {
+		scheduleChatExpire(msg.Mid, msg.From, msg.To, ttl)
+	}
+	// 更新会话, 增加接收者未读数
+	err = msgdao.SessionDaoImpl.UpdateOrCreateSession(msg.From, msg.To, msg.From, msg.Mid, dbMsg.CreateAt)
+	if err != nil {
+		logger.E("update session error %v", err)
+	}
+	return nil
+}
 
+// loadResendMessage 重发的消息以服务端保存的为准, 只能重发自己发送给同一接收者且未撤回的消息
+func loadResendMessage(from int64, msg *message.ChatMessage) error {
+	ms, err := msgdao.GetChatMessage(msg.Mid)
+	if err != nil {
+		return err
+	}
+	if len(ms) == 0 {
+		return errors.New("resend a chat message not exist")
+	}
+	origin := ms[0]
+	if origin.From != from || origin.To != msg.To || origin.Status == msgdao.ChatMessageStatusRecalled {
+		return errors.New("illegal operation")
+	}
+	msg.Type = origin.Type
+	msg.Content = origin.Content
+	msg.SendAt = origin.SendAt
+	return nil
+}
+
+// recallChatMessage 撤回单聊消息, 仅发送者可以在撤回时限内撤回, 撤回后从接收者的离线消息中移除
+func recallChatMessage(from int64, to int64, mid int64) error {
+	ms, err := msgdao.GetChatMessage(mid)
+	if err != nil {
+		return err
+	}
+	if len(ms) == 0 {
+		return errors.New("recall a chat message not exist")
+	}
+	origin := ms[0]
+	sendAt := origin.CreateAt
+	if sendAt == 0 {
+		sendAt = origin.SendAt
+	}
+	if origin.From != from || origin.To != to || origin.Status == msgdao.ChatMessageStatusRecalled {
+		return errors.New("illegal operation")
+	}
+	if time.Now().Unix()-sendAt > config.Messaging.ChatRecallWindow {
+		return errors.New("message recall window expired")
+	}
+	err = msgdao.ChatMsgDaoImpl.UpdateChatMessageStatus(mid, from, to, msgdao.ChatMessageStatusRecalled)
+	if err != nil {
+		return err
+	}
+	err = msgdao.DelOfflineMessage(to, []int64{mid})
+	if err != nil {
+		logger.E("remove recalled offline message error %v", err)
+	}
+	return nil
+}
+
+// deliverChatMessage 写入双方收件箱, 同步给发送者的其他设备, 并投递单聊消息, 接收者不在线时保存离线消息
+func deliverChatMessage(from int64, device int64, m *message.Message, msg *message.ChatMessage) {
+	// 重发的消息已写入过收件箱并同步过
+	switch m.GetAction() {
+	case message.ActionChatMessageResend:
+	case message.ActionChatMessageRecall:
+		// 撤回事件投递给双方的所有设备, 接收者离线时由收件箱同步, 不加入离线消息
+		writeInbox(message.ActionChatMessageRecall, msg.Mid, 0, msg, msg.To, from)
+		syncToSender(from, 0, message.ActionChatMessageRecall, msg)
+		webhook.Publish(webhook.EventChatRecall, msg)
+		if client.IsOnline(msg.To) {
+			dispatchOnline(from, message.ActionChatMessageRecall, msg)
+		}
+		return
+	default:
+		writeInbox(message.ActionChatMessage, msg.Mid, 0, msg, msg.To, from)
+		syncToSender(from, device, message.ActionChatMessage, msg)
+		webhook.Publish(webhook.EventChatMessage, msg)
+		// 接收者是机器人, 由机器人的推送或长轮询接收
+		if bot.Deliver(msg.To, message.ActionChatMessage, msg) {
+			ackNotifyMessage(from, msg.Mid)
+			return
+		}
+	}
 	// 对方不在线, 下发确认包
 	if !client.IsOnline(msg.To) {
 		ackNotifyMessage(from, msg.Mid)
@@ -70,9 +209,14 @@ func handleChatMessage(from int64, device int64, m *message.Message) {
 		if err != nil {
 			logger.E("save offline message error %v", err)
 		}
-		dispatchOffline(from, m)
+		if max := config.Messaging.OfflineMaxMessages; max > 0 {
+			if _, err = msgdao.ChatMsgDaoImpl.TrimOfflineMessage(msg.To, max); err != nil {
+				logger.E("trim offline message error %v", err)
+			}
+		}
+		dispatchOffline(from, msg)
 	} else {
-		dispatchOnline(from, msg)
+		dispatchOnline(from, message.ActionChatMessage, msg)
 	}
 }
 
@@ -80,28 +224,185 @@ func handleChatRecallMessage(from int64, device int64, msg *message.Message) {
 	handleChatMessage(from, device, msg)
 }
 
+// handleChatEditMessage 编辑单聊消息, 仅原发送者可以在编辑时限内编辑, 编辑成功后写入收件箱并通知在线的接收者
+func handleChatEditMessage(from int64, device int64, m *message.Message) {
+	if uid.IsTempId(from) {
+		logger.D("not sign in")
+		client.EnqueueMessage(from, message.NewMessage(0, message.ActionNotifyNeedAuth, ""))
+		return
+	}
+	msg := new(message.ChatMessage)
+	if !unwrap(from, m, msg) {
+		return
+	}
+	msg.From = from
+
+	e := &message.Edit{}
+	err := message.DefaultCodec.Decode([]byte(msg.Content), e)
+	if err != nil {
+		logger.E("decode edit message error %v", err)
+		return
+	}
+	ms, err := msgdao.GetChatMessage(e.Mid)
+	if err != nil || len(ms) == 0 {
+		logger.E("edit a chat message not exist, mid=%d, %v", e.Mid, err)
+		notifyMessageFailed(from, msg.Mid)
+		return
+	}
+	origin := ms[0]
+	sendAt := origin.CreateAt
+	if sendAt == 0 {
+		sendAt = origin.SendAt
+	}
+	now := time.Now().Unix()
+	if origin.From != from || origin.To != msg.To || now-sendAt > config.Messaging.EditWindow {
+		notifyMessageFailed(from, msg.Mid)
+		return
+	}
+
+	r, ok := moderateMessage(from, device, msg.Seq, msg.To, false, msg.Type, &e.Content)
+	if !ok {
+		return
+	}
+	e.EditAt = now
+	err = msgdao.ChatMsgDaoImpl.EditChatMessage(e.Mid, from, e.Content, e.EditAt)
+	if err != nil {
+		logger.E("edit chat message error %v", err)
+		notifyMessageFailed(from, msg.Mid)
+		return
+	}
+	flagForReview(e.Mid, from, msg.To, false, e.Content, r)
+	content, err := message.DefaultCodec.Encode(e)
+	if err != nil {
+		logger.E("encode edit message error %v", err)
+		return
+	}
+	msg.Content = string(content)
+	// 编辑事件的 mid 由服务端分配, 原消息 mid 在编辑内容中
+	msg.Mid, err = msgdao.GetMessageID()
+	if err != nil {
+		logger.E("get message id error %v", err)
+		notifyMessageFailed(from, e.Mid)
+		return
+	}
+
+	ackChatMessage(from, device, msg.Seq, msg.Mid)
+	// 编辑事件写入双方收件箱, 接收者离线时由收件箱同步
+	writeInbox(message.ActionChatMessageEdit, msg.Mid, 0, msg, msg.To, from)
+	syncToSender(from, device, message.ActionChatMessageEdit, msg)
+	webhook.Publish(webhook.EventChatEdit, msg)
+	if client.IsOnline(msg.To) {
+		dispatchOnline(from, message.ActionChatMessageEdit, msg)
+	}
+}
+
+// handleChatReaction 添加或移除单聊消息的表情回应, 并通知对方和回应者的其他设备
+func handleChatReaction(from int64, device int64, m *message.Message) {
+	if uid.IsTempId(from) {
+		logger.D("not sign in")
+		client.EnqueueMessage(from, message.NewMessage(0, message.ActionNotifyNeedAuth, ""))
+		return
+	}
+	r := new(message.Reaction)
+	if !unwrap(from, m, r) {
+		return
+	}
+	r.From = from
+	if !validReaction(r) {
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "invalid reaction"))
+		return
+	}
+
+	ms, err := msgdao.GetChatMessage(r.Mid)
+	if err != nil || len(ms) == 0 {
+		logger.E("reaction to a chat message not exist, mid=%d, %v", r.Mid, err)
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "reaction failed"))
+		return
+	}
+	origin := ms[0]
+	inSession := (origin.From == from && origin.To == r.To) || (origin.From == r.To && origin.To == from)
+	if !inSession || origin.Status == msgdao.ChatMessageStatusRecalled {
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "reaction failed"))
+		return
+	}
+
+	if r.Remove {
+		err = msgdao.ReactionDaoImpl.RemoveReaction(r.Mid, from, r.Emoji)
+	} else {
+		_, err = msgdao.ReactionDaoImpl.AddReaction(r.Mid, from, r.Emoji)
+	}
+	if err != nil {
+		logger.E("update chat message reaction error %v", err)
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "reaction failed"))
+		return
+	}
+	syncToSender(from, device, message.ActionChatMessageReaction, r)
+	enqueueMessage(r.To, message.NewMessage(-1, message.ActionChatMessageReaction, r))
+}
+
+// handleChatRead 单聊已读, 清空已读者的会话未读数, 记录已读位置, 并向对方发送已读回执
+func handleChatRead(from int64, device int64, m *message.Message) {
+	if uid.IsTempId(from) {
+		logger.D("not sign in")
+		client.EnqueueMessage(from, message.NewMessage(0, message.ActionNotifyNeedAuth, ""))
+		return
+	}
+	r := new(message.Read)
+	if !unwrap(from, m, r) {
+		return
+	}
+	r.From = from
+	r.ReadAt = time.Now().Unix()
+
+	mid, err := msgdao.SessionDaoImpl.UpdateUserSessionRead(from, r.To, from, r.Mid)
+	if err == msgdao.ErrReadMidInvalid || err == msgdao.ErrSessionNotExist {
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "read failed, message not exist"))
+		return
+	}
+	if err != nil {
+		logger.E("update session read error %v", err)
+		enqueueMessage(from, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "read failed"))
+		return
+	}
+	r.Mid = mid
+	writeInbox(message.ActionChatMessageRead, r.Mid, 0, r, r.To)
+	enqueueMessage(r.To, message.NewMessage(-1, message.ActionChatMessageRead, r))
+}
+
+func notifyMessageFailed(from int64, mid int64) {
+	notify := message.NewMessage(0, message.ActionMessageFailed, message.NewAckNotify(mid))
+	enqueueMessage(from, notify)
+}
+
 func ackNotifyMessage(from int64, mid int64) {
 	ackNotify := message.NewAckNotify(mid)
 	msg := message.NewMessage(0, message.ActionAckNotify, &ackNotify)
 	client.EnqueueMessage(from, msg)
 }
 
-func ackChatMessage(from int64, device int64, mid int64) {
+// ackChatMessage 确认发送者消息已收到, 确认消息的 seq 为客户端消息 seq, 客户端以此对应服务端分配的 mid
+func ackChatMessage(from int64, device int64, cliSeq int64, mid int64) {
 	ackMsg := message.NewAckMessage(mid, 0)
-	ack := message.NewMessage(0, message.ActionAckMessage, &ackMsg)
+	ack := message.NewMessage(cliSeq, message.ActionAckMessage, &ackMsg)
 	client.EnqueueMessageToDevice(from, device, ack)
 }
 
 // dispatchOffline 接收者不在线, 离线推送
-func dispatchOffline(from int64, message *message.Message) {
-
+func dispatchOffline(from int64, msg *message.ChatMessage) {
+	push.Notify(&push.Message{
+		Uid:     msg.To,
+		From:    from,
+		Mid:     msg.Mid,
+		Type:    msg.Type,
+		Content: msg.Content,
+	})
 }
 
 // dispatchOnline 接收者在线, 直接投递消息
-func dispatchOnline(from int64, msg *message.ChatMessage) {
+func dispatchOnline(from int64, action message.Action, msg *message.ChatMessage) {
 
 	receiverMsg := msg
 	msg.From = from
-	dispatchMsg := message.NewMessage(-1, message.ActionChatMessage, receiverMsg)
+	dispatchMsg := message.NewMessage(-1, action, receiverMsg)
 	client.EnqueueMessage(msg.To, dispatchMsg)
 }
@@ -0,0 +1,125 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/glide-im/glideim/pkg/timingwheel"
+	"time"
+)
+
+// expireSweepBatch 定期清理任务每批处理的过期消息数量
+const expireSweepBatch = 500
+
+var expireTw = timingwheel.NewTimingWheel(time.Second, 3, 20)
+
+// scheduleChatExpire 使用时间轮回调在单聊消息过期时删除消息, 服务重启丢失的任务由定期清理任务处理
+func scheduleChatExpire(mid int64, from int64, to int64, ttl int64) {
+	expireTw.After(time.Duration(ttl) * time.Second).Callback(func() {
+		err := msgdao.ExpireDaoImpl.DeleteChatMessages(mid)
+		if err != nil {
+			logger.E("delete expired chat message error %v", err)
+			return
+		}
+		notifyChatExpired(mid, from, to)
+	})
+}
+
+func notifyChatExpired(mid int64, from int64, to int64) {
+	e := &message.Expired{}
+	e.Mid = mid
+	e.From = from
+	e.To = to
+	m := message.NewMessage(-1, message.ActionChatMessageExpired, e)
+	enqueueMessage(from, m)
+	enqueueMessage(to, m)
+}
+
+// RunExpireSweeper 定期删除已过期的单聊和群消息并通知在线的参与者, 同时清理过期的离线消息, 该方法会阻塞
+func RunExpireSweeper() {
+	ticker := time.NewTicker(time.Duration(config.Messaging.ExpireSweepInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		sweepExpiredMessage()
+	}
+}
+
+func sweepExpiredMessage() {
+	now := time.Now().Unix()
+	for {
+		ms, err := msgdao.ExpireDaoImpl.GetExpiredChatMessages(now, expireSweepBatch)
+		if err != nil {
+			logger.E("load expired chat message error %v", err)
+			break
+		}
+		if len(ms) == 0 {
+			break
+		}
+		var mid []int64
+		for _, m := range ms {
+			mid = append(mid, m.MID)
+		}
+		if err = msgdao.ExpireDaoImpl.DeleteChatMessages(mid...); err != nil {
+			logger.E("delete expired chat message error %v", err)
+			break
+		}
+		for _, m := range ms {
+			notifyChatExpired(m.MID, m.From, m.To)
+		}
+		if len(ms) < expireSweepBatch {
+			break
+		}
+	}
+
+	for {
+		ms, err := msgdao.ExpireDaoImpl.GetExpiredGroupMessages(now, expireSweepBatch)
+		if err != nil {
+			logger.E("load expired group message error %v", err)
+			break
+		}
+		if len(ms) == 0 {
+			break
+		}
+		var mid []int64
+		for _, m := range ms {
+			mid = append(mid, m.MID)
+		}
+		if err = msgdao.ExpireDaoImpl.DeleteGroupMessages(mid...); err != nil {
+			logger.E("delete expired group message error %v", err)
+			break
+		}
+		for _, m := range ms {
+			e := &message.Expired{}
+			e.Mid = m.MID
+			e.From = m.From
+			e.To = m.To
+			content, err := message.DefaultCodec.Encode(e)
+			if err != nil {
+				continue
+			}
+			msg := message.NewChatMessage(m.MID, m.Seq, m.From, m.To, m.Type, string(content), m.SendAt)
+			if err = dispatchExpiredMessage(m.To, &msg); err != nil {
+				logger.D("dispatch group expired message error %v", err)
+			}
+		}
+		if len(ms) < expireSweepBatch {
+			break
+		}
+	}
+
+	if config.Messaging.OfflineExpire <= 0 {
+		return
+	}
+	before := now - config.Messaging.OfflineExpire
+	for {
+		n, err := msgdao.ChatMsgDaoImpl.DeleteExpiredOfflineMessage(before, expireSweepBatch)
+		if err != nil {
+			logger.E("delete expired offline message error %v", err)
+			break
+		}
+		if n < expireSweepBatch {
+			break
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package messaging
+
+import (
+	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/client"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/dao/uid"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"time"
+)
+
+var errForwardNotVisible = errors.New("forward source message not visible")
+
+// handleForward 转发单聊或群消息到另一个单聊或群, 消息内容由服务端根据源消息复制
+func handleForward(from int64, device int64, m *message.Message) {
+	if uid.IsTempId(from) {
+		logger.D("not sign in")
+		client.EnqueueMessage(from, message.NewMessage(0, message.ActionNotifyNeedAuth, ""))
+		return
+	}
+	f := new(message.Forward)
+	if !unwrap(from, m, f) {
+		return
+	}
+	if len(f.Mid) == 0 || len(f.Mid) > config.Messaging.MaxForwardMessages {
+		enqueueMessage2Device(from, device, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "invalid forward messages"))
+		return
+	}
+
+	records, err := loadForwardSource(from, f)
+	if err != nil {
+		logger.E("load forward source message error %v", err)
+		enqueueMessage2Device(from, device, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "forward failed"))
+		return
+	}
+	if f.ToGroup {
+		isMember, err := groupdao.Dao.HasMember(f.To, from)
+		if err != nil || !isMember {
+			enqueueMessage2Device(from, device, message.NewMessage(m.GetSeq(), message.ActionNotifyError, "forward failed"))
+			return
+		}
+	}
+
+	var forwards []*message.ChatMessage
+	now := time.Now().Unix()
+	if f.Merge {
+		c, err := message.DefaultCodec.Encode(records)
+		if err != nil {
+			logger.E("encode chat record error %v", err)
+			return
+		}
+		cm := message.NewChatMessage(0, 0, from, f.To, message.MessageTypeChatRecord, string(c), now)
+		forwards = append(forwards, &cm)
+	} else {
+		for _, r := range records.Messages {
+			cm := message.NewChatMessage(0, 0, from, f.To, r.Type, r.Content, now)
+			forwards = append(forwards, &cm)
+		}
+	}
+
+	result := &message.ForwardResult{}
+	for _, cm := range forwards {
+		cm.Mid, err = msgdao.GetMessageID()
+		if err != nil {
+			logger.E("generate forward message id error %v", err)
+			break
+		}
+		if err = sendMessageAs(from, f.ToGroup, cm); err != nil {
+			logger.E("send forward message error %v", err)
+			break
+		}
+		result.Mid = append(result.Mid, cm.Mid)
+	}
+	enqueueMessage2Device(from, device, message.NewMessage(m.GetSeq(), message.ActionMessageForward, result))
+}
+
+// loadForwardSource 加载转发的源消息, 并检查转发者是否可以看到这些消息, 转发者删除或清空的, 已撤回的和已过期的消息不能转发,
+// 返回的记录与请求的消息顺序一致
+func loadForwardSource(from int64, f *message.Forward) (*message.ChatRecord, error) {
+	records := &message.ChatRecord{}
+	now := time.Now().Unix()
+
+	if f.FromGroup {
+		ms, err := msgdao.GroupMsgDaoImpl.GetVisibleMessages(from, f.Mid...)
+		if err != nil {
+			return nil, err
+		}
+		visible := map[int64]bool{}
+		index := map[int64]*msgdao.GroupMessage{}
+		for _, gm := range ms {
+			v, ok := visible[gm.To]
+			if !ok {
+				v, err = groupdao.Dao.HasMember(gm.To, from)
+				if err != nil {
+					return nil, err
+				}
+				visible[gm.To] = v
+			}
+			if !v || gm.Status == msgdao.ChatMessageStatusRecalled || expired(gm.ExpireAt, now) {
+				return nil, errForwardNotVisible
+			}
+			index[gm.MID] = gm
+		}
+		for _, mid := range f.Mid {
+			gm, ok := index[mid]
+			if !ok {
+				return nil, errForwardNotVisible
+			}
+			records.AddMessage(gm.MID, gm.From, gm.Type, gm.Content, gm.SendAt)
+		}
+		return records, nil
+	}
+
+	ms, err := msgdao.ChatMsgDaoImpl.GetVisibleChatMessages(from, f.Mid...)
+	if err != nil {
+		return nil, err
+	}
+	index := map[int64]*msgdao.ChatMessage{}
+	for _, cm := range ms {
+		if (cm.From != from && cm.To != from) || cm.Status == msgdao.ChatMessageStatusRecalled || expired(cm.ExpireAt, now) {
+			return nil, errForwardNotVisible
+		}
+		index[cm.MID] = cm
+	}
+	for _, mid := range f.Mid {
+		cm, ok := index[mid]
+		if !ok {
+			return nil, errForwardNotVisible
+		}
+		records.AddMessage(cm.MID, cm.From, cm.Type, cm.Content, cm.SendAt)
+	}
+	return records, nil
+}
+
+// expired 设置了过期时间的消息是否已过期
+func expired(expireAt int64, now int64) bool {
+	return expireAt > 0 && expireAt <= now
+}
@@ -1,13 +1,15 @@
 package messaging
 
 import (
+	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/dao/uid"
 	"github.com/glide-im/glideim/im/message"
 	"github.com/glide-im/glideim/pkg/logger"
+	"time"
 )
 
-// handleGroupMsg 分发群消息
+// handleGroupMsg 分发群消息, 新消息及撤回, 编辑事件的 mid 由服务端分配
 func handleGroupMsg(from int64, device int64, msg *message.Message) {
 	if uid.IsTempId(from) {
 		logger.D("not sign in, uid=%d", from)
@@ -20,22 +22,112 @@ func handleGroupMsg(from int64, device int64, msg *message.Message) {
 	groupMsg.From = from
 
 	var err error
-	if msg.GetAction() == message.ActionGroupMessageRecall {
-		err = dispatchRecallMessage(groupMsg.To, groupMsg)
-	} else {
-		err = dispatchGroupMessage(groupMsg.To, groupMsg)
+	var action message.Action = message.ActionGroupMessage
+	syncDevice := device
+	switch msg.GetAction() {
+	case message.ActionGroupMessageRecall:
+		// 撤回事件同步给撤回者的所有设备
+		action = message.ActionGroupMessageRecall
+		syncDevice = 0
+		groupMsg.Mid, err = msgdao.GetMessageID()
+		if err == nil {
+			err = dispatchRecallMessage(groupMsg.To, groupMsg)
+		}
+	case message.ActionGroupMessageEdit:
+		action = message.ActionGroupMessageEdit
+		r, mid, ok := moderateGroupEdit(from, device, groupMsg)
+		if !ok {
+			return
+		}
+		groupMsg.Mid, err = msgdao.GetMessageID()
+		if err == nil {
+			err = dispatchEditMessage(groupMsg.To, groupMsg)
+		}
+		if err == nil {
+			flagForReview(mid, from, groupMsg.To, true, groupMsg.Content, r)
+		}
+	default:
+		cliSeq := groupMsg.Seq
+		claimed, mid := claimSend(from, device, cliSeq)
+		if !claimed {
+			if mid != 0 {
+				ackDuplicateGroupMessage(from, device, cliSeq, mid)
+			}
+			return
+		}
+		r, ok := moderateMessage(from, device, cliSeq, groupMsg.To, true, groupMsg.Type, &groupMsg.Content)
+		if !ok {
+			releaseSend(from, device, cliSeq)
+			return
+		}
+		groupMsg.Mid, err = msgdao.GetMessageID()
+		if err == nil {
+			err = dispatchGroupMessage(groupMsg.To, groupMsg)
+		}
+		if err != nil {
+			releaseSend(from, device, cliSeq)
+		} else {
+			completeSend(from, device, cliSeq, groupMsg.Mid)
+			flagForReview(groupMsg.Mid, from, groupMsg.To, true, groupMsg.Content, r)
+		}
 	}
 	if err != nil {
 		logger.E("dispatch group message error: %v", err)
-		notify := message.NewMessage(0, message.ActionMessageFailed, message.NewAckNotify(groupMsg.Mid))
-		enqueueMessage(from, notify)
+		notifyMessageFailed(from, groupMsg.Mid)
+		return
+	}
+	syncToSender(from, syncDevice, action, groupMsg)
+}
+
+// ackDuplicateGroupMessage 重复发送的群消息, 以原消息的 mid 和群 seq 向重发的设备重新确认
+func ackDuplicateGroupMessage(from int64, device int64, cliSeq int64, mid int64) {
+	gm, err := msgdao.GroupMsgDaoImpl.GetMessage(mid)
+	if err != nil {
+		logger.E("get duplicate group message error %v", err)
+		return
 	}
+	ack := message.NewMessage(cliSeq, message.ActionAckNotify, message.NewAckMessage(mid, gm.Seq))
+	client.EnqueueMessageToDevice(from, device, ack)
 }
 
 func handleGroupRecallMsg(from int64, device int64, msg *message.Message) {
 	handleGroupMsg(from, device, msg)
 }
 
+func handleGroupEditMsg(from int64, device int64, msg *message.Message) {
+	handleGroupMsg(from, device, msg)
+}
+
+// handleGroupReaction 添加或移除群消息的表情回应, 交由群处理并通知群成员, 成功后同步给回应者的其他设备
+func handleGroupReaction(from int64, device int64, msg *message.Message) {
+	if uid.IsTempId(from) {
+		logger.D("not sign in, uid=%d", from)
+		return
+	}
+	r := new(message.Reaction)
+	if !unwrap(from, msg, r) {
+		return
+	}
+	r.From = from
+	if !validReaction(r) {
+		enqueueMessage(from, message.NewMessage(msg.GetSeq(), message.ActionNotifyError, "invalid reaction"))
+		return
+	}
+	content, err := message.DefaultCodec.Encode(r)
+	if err != nil {
+		logger.E("encode reaction error: %v", err)
+		return
+	}
+	groupMsg := message.NewChatMessage(0, 0, from, r.To, 0, string(content), time.Now().Unix())
+	err = dispatchReactionMessage(r.To, &groupMsg)
+	if err != nil {
+		logger.E("dispatch group reaction error: %v", err)
+		enqueueMessage(from, message.NewMessage(msg.GetSeq(), message.ActionNotifyError, "reaction failed"))
+		return
+	}
+	syncToSender(from, device, message.ActionGroupMessageReaction, r)
+}
+
 func handleAckGroupMsgRequest(from int64, device int64, msg *message.Message) {
 	ack := new(message.AckGroupMessage)
 	if !unwrap(from, msg, ack) {
@@ -43,6 +135,12 @@ func handleAckGroupMsgRequest(from int64, device int64, msg *message.Message) {
 	}
 	err := msgdao.UpdateGroupMemberMsgState(ack.Gid, from, ack.Mid, ack.Seq)
 	if err != nil {
-
+		logger.E("update group member message state error: %v", err)
+		return
+	}
+	ackMsg := message.NewChatMessage(ack.Mid, ack.Seq, from, ack.Gid, 0, "", 0)
+	err = dispatchAckMessage(ack.Gid, &ackMsg)
+	if err != nil {
+		logger.E("dispatch group ack message error: %v", err)
 	}
 }
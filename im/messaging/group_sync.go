@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"time"
+)
+
+// syncGroupMessages 用户登录后推送其在各群中错过的消息, 错过的消息由成员确认位置与群最新 seq 计算,
+// 不超过 GroupSyncLimit 条时逐条推送, 否则只推送未读摘要, 没有确认记录的群从 seq 0 开始计算
+func syncGroupMessages(uid int64, device int64) {
+	gid, err := groupdao.Dao.GetMemberGroups(uid)
+	if err != nil {
+		logger.E("sync group message, get member groups error %v", err)
+		return
+	}
+	if len(gid) == 0 {
+		return
+	}
+	states, err := msgdao.GroupMsgDaoImpl.GetGroupsMessageState(gid...)
+	if err != nil {
+		logger.E("sync group message, get group state error %v", err)
+		return
+	}
+	acks, err := msgdao.GroupMsgDaoImpl.GetUserGroupMsgStates(uid, gid...)
+	if err != nil {
+		logger.E("sync group message, get member state error %v", err)
+		return
+	}
+	positions, err := msgdao.VisibilityDaoImpl.GetClearPositions(uid, true, gid...)
+	if err != nil {
+		logger.E("sync group message, get clear position error %v", err)
+		return
+	}
+	ackSeq := map[int64]int64{}
+	for _, a := range acks {
+		ackSeq[a.Gid] = a.LastAckSeq
+	}
+
+	for _, state := range states {
+		// 没有确认记录时视为未确认过任何消息, 错过的消息较多时只推送摘要
+		ack := ackSeq[state.Gid]
+		// 清空过的聊天记录不再推送
+		if positions[state.Gid] > ack {
+			ack = positions[state.Gid]
+		}
+		if state.LastSeq <= ack {
+			continue
+		}
+		if state.LastSeq-ack > config.Messaging.GroupSyncLimit {
+			summary := message.NewGroupSummary(state.Gid, ack, state.LastSeq, state.LastMID, state.LastMsgAt)
+			enqueueMessage2Device(uid, device, message.NewMessage(-1, message.ActionGroupMessageSummary, summary))
+			continue
+		}
+		ms, err := msgdao.GroupMsgDaoImpl.GetGroupMessageSeqSpan(state.Gid, ack+1, state.LastSeq)
+		if err != nil {
+			logger.E("sync group message, get message error %v", err)
+			continue
+		}
+		var mid []int64
+		for _, m := range ms {
+			mid = append(mid, m.MID)
+		}
+		hidden, err := msgdao.VisibilityDaoImpl.GetHiddenMessages(uid, mid...)
+		if err != nil {
+			logger.E("sync group message, get hidden message error %v", err)
+			continue
+		}
+		for _, m := range missedGroupMessages(ms, hidden, time.Now().Unix()) {
+			cm := message.NewChatMessage(m.MID, m.Seq, m.From, m.To, m.Type, m.Content, m.SendAt)
+			enqueueMessage2Device(uid, device, message.NewMessage(-1, message.ActionGroupMessage, &cm))
+		}
+	}
+}
+
+// missedGroupMessages 过滤掉已撤回, 已过期和用户删除的消息
+func missedGroupMessages(ms []*msgdao.GroupMessage, hidden []int64, now int64) []*msgdao.GroupMessage {
+	h := map[int64]bool{}
+	for _, mid := range hidden {
+		h[mid] = true
+	}
+	//goland:noinspection GoPreferNilSlice
+	missed := []*msgdao.GroupMessage{}
+	for _, m := range ms {
+		if h[m.MID] || m.Status == msgdao.ChatMessageStatusRecalled || (m.ExpireAt > 0 && m.ExpireAt <= now) {
+			continue
+		}
+		missed = append(missed, m)
+	}
+	return missed
+}
@@ -0,0 +1,20 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"testing"
+)
+
+func TestMissedGroupMessages(t *testing.T) {
+	ms := []*msgdao.GroupMessage{
+		{MID: 1, Seq: 1},
+		{MID: 2, Seq: 2, Status: msgdao.ChatMessageStatusRecalled},
+		{MID: 3, Seq: 3, ExpireAt: 100},
+		{MID: 4, Seq: 4, ExpireAt: 300},
+		{MID: 5, Seq: 5},
+	}
+	missed := missedGroupMessages(ms, []int64{5}, 200)
+	if len(missed) != 2 || missed[0].MID != 1 || missed[1].MID != 4 {
+		t.Errorf("unexpected missed messages %v", missed)
+	}
+}
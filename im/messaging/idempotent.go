@@ -0,0 +1,57 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/pkg/logger"
+	"time"
+)
+
+func sendWindow() time.Duration {
+	return time.Duration(config.Messaging.SendIdempotentWindow) * time.Second
+}
+
+// claimSend 以 (from, device, cliSeq) 标记一次发送, 返回 false 表示窗口期内的重复发送,
+// 此时若原消息已处理完成, 返回原消息 ID, 原消息仍在处理中则返回 0.
+// cliSeq 为 0 的消息 (如服务端构造的消息) 不参与去重.
+func claimSend(from int64, device int64, cliSeq int64) (bool, int64) {
+	if cliSeq <= 0 {
+		return true, 0
+	}
+	ok, err := msgdao.Cache.ClaimSend(from, device, cliSeq, sendWindow())
+	if err != nil {
+		// 缓存不可用时不阻塞发送
+		logger.E("claim send error %v", err)
+		return true, 0
+	}
+	if ok {
+		return true, 0
+	}
+	mid, err := msgdao.Cache.GetSendResult(from, device, cliSeq)
+	if err != nil {
+		logger.E("get send result error %v", err)
+	}
+	return false, mid
+}
+
+// completeSend 记录发送成功的消息 ID, 用于重复发送时重新确认
+func completeSend(from int64, device int64, cliSeq int64, mid int64) {
+	if cliSeq <= 0 {
+		return
+	}
+	err := msgdao.Cache.CompleteSend(from, device, cliSeq, mid, sendWindow())
+	if err != nil {
+		logger.E("complete send error %v", err)
+	}
+}
+
+// releaseSend 发送失败, 移除标记以允许客户端重试
+func releaseSend(from int64, device int64, cliSeq int64) {
+	if cliSeq <= 0 {
+		return
+	}
+	err := msgdao.Cache.ReleaseSend(from, device, cliSeq)
+	if err != nil {
+		logger.E("release send error %v", err)
+	}
+}
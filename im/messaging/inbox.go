@@ -0,0 +1,15 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+)
+
+// writeInbox 将事件写入用户收件箱, 设备通过收件箱 seq 同步
+func writeInbox(action message.Action, mid int64, gid int64, data interface{}, uid ...int64) {
+	err := msgdao.AddInboxEvent(string(action), mid, gid, data, uid...)
+	if err != nil {
+		logger.E("write inbox event error %v", err)
+	}
+}
@@ -27,6 +27,26 @@ func dispatchRecallMessage(gid int64, msg *message.ChatMessage) error {
 	return group.DispatchRecallMessage(gid, msg)
 }
 
+func dispatchEditMessage(gid int64, msg *message.ChatMessage) error {
+	return group.DispatchEditMessage(gid, msg)
+}
+
+func dispatchReactionMessage(gid int64, msg *message.ChatMessage) error {
+	return group.DispatchReactionMessage(gid, msg)
+}
+
+func dispatchExpiredMessage(gid int64, msg *message.ChatMessage) error {
+	return group.DispatchExpiredMessage(gid, msg)
+}
+
+func dispatchTypingMessage(gid int64, msg *message.ChatMessage) error {
+	return group.DispatchTypingMessage(gid, msg)
+}
+
+func dispatchAckMessage(gid int64, msg *message.ChatMessage) error {
+	return group.DispatchAckMessage(gid, msg)
+}
+
 func enqueueMessage(uid int64, message *message.Message) {
 	err := client.EnqueueMessage(uid, message)
 	if err != nil {
@@ -40,3 +60,12 @@ func enqueueMessage2Device(uid int64, device int64, message *message.Message) {
 		logger.E("%v", err)
 	}
 }
+
+// syncToSender 将用户自己发出的消息同步给该用户的其他在线设备, device 为 0 (服务端代发) 时同步给所有设备
+func syncToSender(from int64, device int64, action message.Action, data interface{}) {
+	m := message.NewMessage(-1, message.ActionMessageSentSync, message.NewSentSync(action, data))
+	err := client.EnqueueMessageToOtherDevices(from, device, m)
+	if err != nil && err != client.ErrClientNotExist {
+		logger.E("%v", err)
+	}
+}
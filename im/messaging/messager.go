@@ -1,6 +1,7 @@
 package messaging
 
 import (
+	"github.com/glide-im/glideim/im/dao/msgdao"
 	"github.com/glide-im/glideim/im/message"
 	"github.com/glide-im/glideim/im/statistics"
 	"github.com/glide-im/glideim/pkg/logger"
@@ -10,18 +11,25 @@ import (
 // execPool 100 capacity goroutine pool, 假设每个消息处理需要10ms, 一个协程则每秒能处理100条消息
 var execPool *ants.Pool
 
-var messageHandlerFunMap = map[message.Action]func(from int64, device int64, msg *message.Message){
-	message.ActionGroupMessageRecall: handleGroupRecallMsg,
-	message.ActionChatMessageRecall:  handleChatRecallMessage,
-	message.ActionChatMessage:        handleChatMessage,
-	message.ActionChatMessageRetry:   handleChatMessage,
-	message.ActionChatMessageResend:  handleChatMessage,
-	message.ActionGroupMessage:       handleGroupMsg,
-	message.ActionCSMessage:          handleCustomerServiceMsg,
-	message.ActionAckRequest:         handleAckRequest,
-	message.ActionAckGroupMsg:        handleAckGroupMsgRequest,
-	message.ActionClientCustom:       handleClientCustom,
-	message.ActionApiAuth:            handleAuth,
+var messageHandlerFunMap = map[message.Action]HandlerFunc{
+	message.ActionGroupMessageRecall:   handleGroupRecallMsg,
+	message.ActionChatMessageRecall:    handleChatRecallMessage,
+	message.ActionGroupMessageEdit:     handleGroupEditMsg,
+	message.ActionChatMessageEdit:      handleChatEditMessage,
+	message.ActionChatMessageReaction:  handleChatReaction,
+	message.ActionChatMessageRead:      handleChatRead,
+	message.ActionGroupMessageReaction: handleGroupReaction,
+	message.ActionChatMessage:          handleChatMessage,
+	message.ActionChatMessageRetry:     handleChatMessage,
+	message.ActionChatMessageResend:    handleChatMessage,
+	message.ActionGroupMessage:         handleGroupMsg,
+	message.ActionCSMessage:            handleCustomerServiceMsg,
+	message.ActionAckRequest:           handleAckRequest,
+	message.ActionAckGroupMsg:          handleAckGroupMsgRequest,
+	message.ActionClientCustom:         handleClientCustom,
+	message.ActionMessageForward:       handleForward,
+	message.ActionTyping:               handleTyping,
+	message.ActionApiAuth:              handleAuth,
 }
 
 func init() {
@@ -36,23 +44,18 @@ func init() {
 	}
 }
 
+// Init 启动消息处理的后台任务, 需要在处理消息前调用
+func Init() {
+	go expireTypingLoop()
+}
+
 // handleMessage 处理接收到的所有类型消息, 所有消息处理的入口
 func handleMessage(from int64, device int64, msg *message.Message) error {
 	logger.D("new message: uid=%d, %v", from, msg)
+	h := getChain()
 	err := execPool.Submit(func() {
 		statistics.SMsgInput()
-		h, ok := messageHandlerFunMap[message.Action(msg.GetAction())]
-		if ok {
-			h(from, device, msg)
-			return
-		}
-		switch msg.GetAction() {
-		case message.ActionHeartbeat:
-			handleHeartbeat(from, device, msg)
-		default:
-			enqueueMessage(from, message.NewMessage(-1, message.ActionNotifyError, "unknown action"))
-			logger.W("receive a unknown action message: " + string(msg.GetAction()))
-		}
+		h(from, device, msg)
 	})
 	if err != nil {
 		if err == ants.ErrPoolOverload {
@@ -69,6 +72,22 @@ func handleMessage(from int64, device int64, msg *message.Message) error {
 	return nil
 }
 
+// dispatch 按动作分发消息到处理函数, 位于中间件链的最内层
+func dispatch(from int64, device int64, msg *message.Message) {
+	h, ok := getHandler(message.Action(msg.GetAction()))
+	if ok {
+		h(from, device, msg)
+		return
+	}
+	switch msg.GetAction() {
+	case message.ActionHeartbeat:
+		handleHeartbeat(from, device, msg)
+	default:
+		enqueueMessage(from, message.NewMessage(-1, message.ActionNotifyError, "unknown action"))
+		logger.W("receive a unknown action message: " + string(msg.GetAction()))
+	}
+}
+
 func handleHeartbeat(from int64, device int64, msg *message.Message) {
 	// TODO 2021-11-15 处理心跳消息
 }
@@ -79,6 +98,18 @@ func handleAckRequest(from int64, device int64, msg *message.Message) {
 	if !unwrap(from, msg, ackMsg) {
 		return
 	}
+	// 只能确认发给自己的消息, 确认通知发给服务端记录的发送者
+	ms, err := msgdao.GetChatMessage(ackMsg.Mid)
+	if err != nil || len(ms) == 0 {
+		logger.E("ack a chat message not exist, mid=%d, %v", ackMsg.Mid, err)
+		return
+	}
+	origin := ms[0]
+	if origin.To != from {
+		logger.D("ack a chat message not received, uid=%d, mid=%d", from, ackMsg.Mid)
+		return
+	}
+	ackMsg.From = origin.From
 	ackNotify := message.NewMessage(0, message.ActionAckNotify, ackMsg)
 	// 通知发送者, 对方已收到消息
 	enqueueMessage(ackMsg.From, ackNotify)
@@ -108,6 +139,46 @@ func onHandleMessagePanic(i interface{}) {
 	logger.E("handler message panic, %v", i)
 }
 
+// SendMessageAs 以 from 的身份发送服务端构造的消息, 未分配 mid 时分配新的 mid, 用于机器人等通过 HTTP 接口发送消息
+func SendMessageAs(from int64, group bool, msg *message.ChatMessage) error {
+	if msg.Mid == 0 {
+		mid, err := msgdao.GetMessageID()
+		if err != nil {
+			return err
+		}
+		msg.Mid = mid
+	}
+	return sendMessageAs(from, group, msg)
+}
+
+// sendMessageAs 以 from 的身份发送服务端构造的消息, 消息的 mid 由调用者分配, 保存, 确认, 投递与客户端发送一致
+func sendMessageAs(from int64, group bool, msg *message.ChatMessage) error {
+	msg.From = from
+	r, err := moderateServerMessage(from, group, msg)
+	if err != nil {
+		return err
+	}
+	if group {
+		err = dispatchGroupMessage(msg.To, msg)
+		if err == nil {
+			syncToSender(from, 0, message.ActionGroupMessage, msg)
+			flagForReview(msg.Mid, from, msg.To, true, msg.Content, r)
+		}
+		return err
+	}
+	m, err := message.NewJsonMessage(-1, message.ActionChatMessage, msg)
+	if err != nil {
+		return err
+	}
+	if err = saveChatMessage(msg); err != nil {
+		return err
+	}
+	flagForReview(msg.Mid, from, msg.To, false, msg.Content, r)
+	ackChatMessage(from, 0, msg.Seq, msg.Mid)
+	deliverChatMessage(from, 0, m, msg)
+	return nil
+}
+
 // unwrap 解包, 反序列化消息包中数据到对象
 func unwrap(from int64, msg *message.Message, to interface{}) bool {
 	err := msg.DeserializeData(to)
@@ -0,0 +1,74 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/im/message"
+	"sync"
+)
+
+// HandlerFunc 消息处理函数
+type HandlerFunc func(from int64, device int64, msg *message.Message)
+
+// Middleware 包装消息处理函数, 可以在调用 next 前后检查, 改写或记录消息, 不调用 next 即拦截消息.
+// 中间件作用于所有动作, 包括心跳和未注册的动作, 只需要处理部分动作时使用 ForActions.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+var (
+	handlerMu   sync.RWMutex
+	middlewares []Middleware
+	// chain 由中间件包装 dispatch 组成, 注册中间件时重新构建
+	chain HandlerFunc = dispatch
+)
+
+// Use 添加中间件, 先添加的中间件在外层, 即先于后添加的中间件看到消息, 晚于其看到处理结果.
+// 应在开始处理消息前调用.
+func Use(m ...Middleware) {
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	middlewares = append(middlewares, m...)
+	chain = buildChain(dispatch, middlewares)
+}
+
+// RegisterHandler 注册动作的处理函数, 可以注册自定义动作, 已注册的动作将被替换
+func RegisterHandler(action message.Action, h HandlerFunc) {
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	messageHandlerFunMap[action] = h
+}
+
+// ForActions 使中间件只作用于指定的动作, 其他动作直接交给下一个处理函数
+func ForActions(m Middleware, actions ...message.Action) Middleware {
+	set := map[message.Action]bool{}
+	for _, a := range actions {
+		set[a] = true
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := m(next)
+		return func(from int64, device int64, msg *message.Message) {
+			if set[message.Action(msg.GetAction())] {
+				wrapped(from, device, msg)
+			} else {
+				next(from, device, msg)
+			}
+		}
+	}
+}
+
+func buildChain(h HandlerFunc, ms []Middleware) HandlerFunc {
+	for i := len(ms) - 1; i >= 0; i-- {
+		h = ms[i](h)
+	}
+	return h
+}
+
+func getChain() HandlerFunc {
+	handlerMu.RLock()
+	defer handlerMu.RUnlock()
+	return chain
+}
+
+func getHandler(action message.Action) (HandlerFunc, bool) {
+	handlerMu.RLock()
+	defer handlerMu.RUnlock()
+	h, ok := messageHandlerFunMap[action]
+	return h, ok
+}
@@ -0,0 +1,48 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/im/message"
+	"reflect"
+	"testing"
+)
+
+func TestBuildChain(t *testing.T) {
+	var trace []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(from int64, device int64, msg *message.Message) {
+				trace = append(trace, name+".before")
+				next(from, device, msg)
+				trace = append(trace, name+".after")
+			}
+		}
+	}
+	h := buildChain(func(from int64, device int64, msg *message.Message) {
+		trace = append(trace, "handler")
+	}, []Middleware{record("a"), record("b")})
+
+	h(1, 0, message.NewMessage(0, message.ActionChatMessage, ""))
+	expect := []string{"a.before", "b.before", "handler", "b.after", "a.after"}
+	if !reflect.DeepEqual(trace, expect) {
+		t.Errorf("expect %v, got %v", expect, trace)
+	}
+}
+
+func TestForActions(t *testing.T) {
+	handled := 0
+	block := func(next HandlerFunc) HandlerFunc {
+		return func(from int64, device int64, msg *message.Message) {}
+	}
+	h := buildChain(func(from int64, device int64, msg *message.Message) {
+		handled++
+	}, []Middleware{ForActions(block, message.ActionTyping)})
+
+	h(1, 0, message.NewMessage(0, message.ActionTyping, ""))
+	if handled != 0 {
+		t.Error("expect typing message blocked")
+	}
+	h(1, 0, message.NewMessage(0, message.ActionChatMessage, ""))
+	if handled != 1 {
+		t.Error("expect chat message handled")
+	}
+}
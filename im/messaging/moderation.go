@@ -0,0 +1,107 @@
+package messaging
+
+import (
+	"errors"
+	"github.com/glide-im/glideim/im/client"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/moderation"
+	"github.com/glide-im/glideim/pkg/logger"
+	"strings"
+	"time"
+)
+
+// moderateMessage 审核用户发送的内容, 需要屏蔽时直接替换 text, 拒绝时通知发送者的当前设备, 返回 false 表示不能发送.
+// 审核出错时放行, 不阻塞消息发送.
+func moderateMessage(from int64, device int64, cliSeq int64, to int64, group bool, typ int32, text *string) (*moderation.Result, bool) {
+	c := &moderation.Content{
+		From:  from,
+		To:    to,
+		Group: group,
+		Type:  typ,
+		Text:  *text,
+	}
+	r, err := moderation.Default.Moderate(c)
+	if err != nil {
+		logger.E("moderate message error %v", err)
+		return nil, true
+	}
+	if r.Reject {
+		logger.D("message rejected by moderation, from=%d, to=%d, %s", from, to, r.Reason)
+		rejected := message.NewMessage(cliSeq, message.ActionMessageRejected, message.NewRejected(to, group, r.Reason))
+		client.EnqueueMessageToDevice(from, device, rejected)
+		return r, false
+	}
+	*text = r.Text
+	return r, true
+}
+
+// ErrMessageRejected 服务端构造的消息被内容审核拒绝
+var ErrMessageRejected = errors.New("message rejected by moderation")
+
+// moderateServerMessage 审核服务端以用户或机器人身份发送的消息, 如定时消息, 转发和机器人消息, 屏蔽时直接替换消息内容.
+// 发送者可能没有在线设备, 拒绝时只返回错误, 由调用者处理. 审核出错时放行.
+func moderateServerMessage(from int64, group bool, msg *message.ChatMessage) (*moderation.Result, error) {
+	c := &moderation.Content{
+		From:  from,
+		To:    msg.To,
+		Group: group,
+		Type:  msg.Type,
+		Text:  msg.Content,
+	}
+	r, err := moderation.Default.Moderate(c)
+	if err != nil {
+		logger.E("moderate message error %v", err)
+		return nil, nil
+	}
+	if r.Reject {
+		logger.D("message rejected by moderation, from=%d, to=%d, %s", from, msg.To, r.Reason)
+		return r, ErrMessageRejected
+	}
+	msg.Content = r.Text
+	return r, nil
+}
+
+// moderateGroupEdit 审核群消息编辑后的内容, 屏蔽后重新编码到消息中, 返回被编辑的消息 ID
+func moderateGroupEdit(from int64, device int64, msg *message.ChatMessage) (*moderation.Result, int64, bool) {
+	e := &message.Edit{}
+	if err := message.DefaultCodec.Decode([]byte(msg.Content), e); err != nil {
+		// 格式错误的编辑消息由群处理时拒绝
+		return nil, 0, true
+	}
+	origin := e.Content
+	r, ok := moderateMessage(from, device, msg.Seq, msg.To, true, msg.Type, &e.Content)
+	if !ok {
+		return r, e.Mid, false
+	}
+	if e.Content != origin {
+		content, err := message.DefaultCodec.Encode(e)
+		if err != nil {
+			logger.E("encode edit message error %v", err)
+			return r, e.Mid, false
+		}
+		msg.Content = string(content)
+	}
+	return r, e.Mid, true
+}
+
+// flagForReview 记录标记待人工审核的消息, 消息已正常发送
+func flagForReview(mid int64, from int64, to int64, group bool, content string, r *moderation.Result) {
+	if r == nil || !r.Flag {
+		return
+	}
+	err := msgdao.ModerationDaoImpl.AddReview(&msgdao.ModerationReview{
+		MID:      mid,
+		Group:    group,
+		From:     from,
+		To:       to,
+		Content:  content,
+		Hits:     strings.Join(r.Hits, ","),
+		Reason:   r.Reason,
+		Status:   msgdao.ModerationReviewPending,
+		CreateAt: time.Now().Unix(),
+	})
+	if err != nil {
+		logger.E("add moderation review error %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/moderation"
+	"testing"
+)
+
+func TestModerateServerMessage(t *testing.T) {
+	defer func(p *moderation.Pipeline) { moderation.Default = p }(moderation.Default)
+	moderation.Default = moderation.NewPipeline(&moderation.StubModerator{
+		Results: map[string]*moderation.Result{
+			"spam": {Reject: true, Reason: "spam"},
+			"bad":  {Text: "***"},
+		},
+	})
+
+	msg := message.NewChatMessage(1, 0, 1001, 543602, 1, "spam", 0)
+	if _, err := moderateServerMessage(1001, false, &msg); err != ErrMessageRejected {
+		t.Errorf("expect %v, got %v", ErrMessageRejected, err)
+	}
+
+	msg.Content = "bad"
+	if _, err := moderateServerMessage(1001, true, &msg); err != nil {
+		t.Error(err)
+	}
+	if msg.Content != "***" {
+		t.Errorf("expect masked content, got %s", msg.Content)
+	}
+
+	msg.Content = "hello"
+	if _, err := moderateServerMessage(1001, false, &msg); err != nil || msg.Content != "hello" {
+		t.Errorf("expect pass, got %v, %s", err, msg.Content)
+	}
+}
@@ -0,0 +1,29 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/message"
+	"unicode"
+	"unicode/utf8"
+)
+
+// validReaction 检查表情回应的长度, 添加时还需要在配置允许的表情中, 移除不检查允许的表情, 以便移除配置修改前的回应
+func validReaction(r *message.Reaction) bool {
+	if r.Emoji == "" || !utf8.ValidString(r.Emoji) || utf8.RuneCountInString(r.Emoji) > config.Messaging.ReactionMaxLength {
+		return false
+	}
+	for _, c := range r.Emoji {
+		if unicode.IsSpace(c) || unicode.IsControl(c) {
+			return false
+		}
+	}
+	if r.Remove || len(config.Messaging.ReactionEmojis) == 0 {
+		return true
+	}
+	for _, e := range config.Messaging.ReactionEmojis {
+		if e == r.Emoji {
+			return true
+		}
+	}
+	return false
+}
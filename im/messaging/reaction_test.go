@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/message"
+	"testing"
+)
+
+func TestValidReaction(t *testing.T) {
+	defer func(emojis []string) {
+		config.Messaging.ReactionEmojis = emojis
+	}(config.Messaging.ReactionEmojis)
+	config.Messaging.ReactionEmojis = []string{"👍", "❤️"}
+
+	cases := []struct {
+		emoji  string
+		remove bool
+		expect bool
+	}{
+		{"👍", false, true},
+		{"❤️", false, true},
+		{"😂", false, false},
+		{"😂", true, true},
+		{"", false, false},
+		{"👍 ", true, false},
+		{"\xff", true, false},
+		{"👍👍👍👍👍👍👍👍👍", true, false},
+	}
+	for _, c := range cases {
+		r := &message.Reaction{}
+		r.Emoji = c.emoji
+		r.Remove = c.remove
+		if got := validReaction(r); got != c.expect {
+			t.Errorf("validReaction(%q, remove=%v) = %v, expect %v", c.emoji, c.remove, got, c.expect)
+		}
+	}
+
+	config.Messaging.ReactionEmojis = nil
+	r := &message.Reaction{}
+	r.Emoji = "😂"
+	if !validReaction(r) {
+		t.Errorf("any emoji should be allowed when the allowed set is empty")
+	}
+}
@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"time"
+)
+
+// scheduleBatch 每次检查最多发送的到期定时消息数量
+const scheduleBatch = 200
+
+// RunScheduler 定期检查到期的定时消息并发送, 定时消息保存在数据库中, 服务重启后继续发送, 该方法会阻塞
+func RunScheduler() {
+	ticker := time.NewTicker(time.Duration(config.Messaging.ScheduleCheckInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		sendDueScheduledMessage()
+	}
+}
+
+// sendDueScheduledMessage 分批发送到期的定时消息, 批次中有消息未能发送时停止, 等下次检查再重试, 避免反复读取同一批消息
+func sendDueScheduledMessage() {
+	for {
+		ms, err := msgdao.ScheduledDaoImpl.GetDueScheduledMessages(time.Now().Unix(), scheduleBatch)
+		if err != nil {
+			logger.E("load scheduled message error %v", err)
+			return
+		}
+		for _, m := range ms {
+			if !sendScheduledMessage(m) {
+				return
+			}
+		}
+		if len(ms) < scheduleBatch {
+			return
+		}
+	}
+}
+
+// sendScheduledMessage 以发送者的身份将定时消息交给正常的消息处理流程, 保存, 确认, 离线消息与客户端发送一致,
+// 返回定时消息是否已不再等待发送, 发送失败时恢复为待发送并返回 false
+func sendScheduledMessage(m *msgdao.ScheduledMessage) bool {
+	mid, err := msgdao.GetMessageID()
+	if err != nil {
+		logger.E("generate scheduled message id error %v", err)
+		return false
+	}
+	claimed, err := msgdao.ScheduledDaoImpl.ClaimScheduledMessage(m.ID, mid)
+	if err != nil {
+		logger.E("claim scheduled message error %v", err)
+		return false
+	}
+	if !claimed {
+		// 已被取消或已由其他实例发送
+		return true
+	}
+
+	chatMsg := message.NewChatMessage(mid, 0, m.From, m.To, m.Type, m.Content, time.Now().Unix())
+	err = sendMessageAs(m.From, m.Group, &chatMsg)
+	if err == nil {
+		return true
+	}
+	logger.E("send scheduled message error %v", err)
+	// 被内容审核拒绝的消息重试也不会成功, 标记为失败, 其他错误恢复为待发送, 下次检查时重试
+	if err == ErrMessageRejected {
+		if err = msgdao.ScheduledDaoImpl.FailScheduledMessage(m.ID, mid); err != nil {
+			logger.E("mark scheduled message failed error %v", err)
+		}
+		return true
+	}
+	if err = msgdao.ScheduledDaoImpl.ReleaseScheduledMessage(m.ID, mid); err != nil {
+		logger.E("release scheduled message error %v", err)
+	}
+	return false
+}
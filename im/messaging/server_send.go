@@ -0,0 +1,123 @@
+package messaging
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/client"
+	"github.com/glide-im/glideim/im/dao/uid"
+	"github.com/glide-im/glideim/im/message"
+	"time"
+)
+
+// 服务端发送的消息类型
+const (
+	SendKindChat   = "chat"
+	SendKindGroup  = "group"
+	SendKindCustom = "custom"
+)
+
+var (
+	ErrServerApiDisabled = errors.New("server api is disabled")
+	ErrInvalidSender     = errors.New("sender must be a system or bot uid")
+	ErrInvalidKind       = errors.New("invalid message kind")
+	ErrInvalidTargets    = errors.New("no targets or too many targets")
+	ErrEmptyContent      = errors.New("empty message content")
+
+	errInvalidReceiver = errors.New("invalid receiver")
+)
+
+// SendRequest 服务端发送消息请求, 以系统账号或机器人向多个接收者发送同一条消息
+type SendRequest struct {
+	From int64
+	// Kind 消息类型, chat 单聊, group 群聊, custom 自定义消息
+	Kind string
+	// To 单聊和自定义消息为接收者 uid, 群聊为群 ID
+	To      []int64
+	Type    int32
+	Content string
+}
+
+// SendResult 每个接收者的发送结果, 自定义消息不保存, Mid 为 0
+type SendResult struct {
+	To    int64
+	Mid   int64
+	Error string `json:",omitempty"`
+}
+
+// CheckApiKey 检查服务端接口密钥是否为配置中的密钥
+func CheckApiKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, k := range config.ServerApi.Keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// SendInterface 服务端批量发送消息的实现, 不运行消息服务的进程通过 rpc 转发给消息服务
+type SendInterface func(req *SendRequest) ([]*SendResult, error)
+
+var sender SendInterface = sendMessages
+
+// SendMessages 服务端批量发送消息, 见 sendMessages
+func SendMessages(req *SendRequest) ([]*SendResult, error) {
+	return sender(req)
+}
+
+func SetSendInterfaceImpl(i SendInterface) {
+	sender = i
+}
+
+// sendMessages 服务端批量发送消息, 单聊和群聊消息与客户端发送的消息一样保存, 确认和投递, 接收者离线时加入离线消息,
+// 自定义消息不保存, 只投递给在线用户. 请求参数错误时返回错误, 单个接收者发送失败记录在对应的结果中
+func sendMessages(req *SendRequest) ([]*SendResult, error) {
+	if !config.ServerApi.Enable {
+		return nil, ErrServerApiDisabled
+	}
+	if !uid.IsSystemId(req.From) {
+		return nil, ErrInvalidSender
+	}
+	if req.Kind != SendKindChat && req.Kind != SendKindGroup && req.Kind != SendKindCustom {
+		return nil, ErrInvalidKind
+	}
+	if len(req.To) == 0 || len(req.To) > config.ServerApi.MaxTargets {
+		return nil, ErrInvalidTargets
+	}
+	if req.Content == "" {
+		return nil, ErrEmptyContent
+	}
+
+	now := time.Now().Unix()
+	results := make([]*SendResult, 0, len(req.To))
+	for _, to := range req.To {
+		r := &SendResult{To: to}
+		var err error
+		switch req.Kind {
+		case SendKindChat:
+			if !uid.IsUserId(to) {
+				err = errInvalidReceiver
+				break
+			}
+			cm := message.NewChatMessage(0, 0, req.From, to, req.Type, req.Content, now)
+			err = SendMessageAs(req.From, false, &cm)
+			r.Mid = cm.Mid
+		case SendKindGroup:
+			cm := message.NewChatMessage(0, 0, req.From, to, req.Type, req.Content, now)
+			err = SendMessageAs(req.From, true, &cm)
+			r.Mid = cm.Mid
+		case SendKindCustom:
+			m := message.NewMessage(0, message.ActionClientCustom, message.NewClientCustom(req.From, to, req.Type, req.Content))
+			err = client.EnqueueMessage(to, m)
+		}
+		if err != nil {
+			r.Mid = 0
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,45 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"testing"
+)
+
+func TestSendMessages_Validate(t *testing.T) {
+	defer func(c config.ServerApiConf) { *config.ServerApi = c }(*config.ServerApi)
+	config.ServerApi.Enable = true
+	config.ServerApi.MaxTargets = 2
+
+	cases := []struct {
+		name string
+		req  *SendRequest
+		err  error
+	}{
+		{"user sender", &SendRequest{From: 543602, Kind: SendKindChat, To: []int64{1}, Content: "hi"}, ErrInvalidSender},
+		{"unknown kind", &SendRequest{From: 1001, Kind: "notify", To: []int64{1}, Content: "hi"}, ErrInvalidKind},
+		{"no targets", &SendRequest{From: 1001, Kind: SendKindChat, Content: "hi"}, ErrInvalidTargets},
+		{"too many targets", &SendRequest{From: 1001, Kind: SendKindChat, To: []int64{1, 2, 3}, Content: "hi"}, ErrInvalidTargets},
+		{"empty content", &SendRequest{From: 1001, Kind: SendKindGroup, To: []int64{1}}, ErrEmptyContent},
+	}
+	for _, c := range cases {
+		if _, err := SendMessages(c.req); err != c.err {
+			t.Errorf("%s: expect %v, got %v", c.name, c.err, err)
+		}
+	}
+
+	config.ServerApi.Enable = false
+	if _, err := SendMessages(cases[0].req); err != ErrServerApiDisabled {
+		t.Errorf("expect %v, got %v", ErrServerApiDisabled, err)
+	}
+}
+
+func TestCheckApiKey(t *testing.T) {
+	defer func(keys []string) { config.ServerApi.Keys = keys }(config.ServerApi.Keys)
+	config.ServerApi.Keys = []string{"k1", "k2"}
+
+	for key, expect := range map[string]bool{"k1": true, "k2": true, "k3": false, "": false} {
+		if CheckApiKey(key) != expect {
+			t.Errorf("CheckApiKey(%q) expect %v", key, expect)
+		}
+	}
+}
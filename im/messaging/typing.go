@@ -0,0 +1,110 @@
+package messaging
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/client"
+	"github.com/glide-im/glideim/im/dao/uid"
+	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
+	"sync"
+	"time"
+)
+
+// typingKey 正在输入状态的标识, 同一发送者在同一会话中只有一个状态
+type typingKey struct {
+	from  int64
+	to    int64
+	group bool
+}
+
+type typingState struct {
+	lastAt   time.Time
+	expireAt time.Time
+}
+
+var typingMu = sync.Mutex{}
+var typings = map[typingKey]*typingState{}
+
+// handleTyping 转发正在输入事件, 事件不保存, 不确认, 也不作为离线消息
+func handleTyping(from int64, device int64, m *message.Message) {
+	if uid.IsTempId(from) {
+		return
+	}
+	t := new(message.Typing)
+	if !unwrap(from, m, t) {
+		return
+	}
+	t.From = from
+	key := typingKey{from: from, to: t.To, group: t.Group}
+	now := time.Now()
+
+	typingMu.Lock()
+	s, ok := typings[key]
+	if t.Stop {
+		if !ok {
+			typingMu.Unlock()
+			return
+		}
+		delete(typings, key)
+	} else {
+		// 限流, 间隔内重复的输入事件直接丢弃, 只延长过期时间
+		if ok && now.Sub(s.lastAt) < time.Duration(config.Messaging.TypingInterval)*time.Second {
+			s.expireAt = now.Add(time.Duration(config.Messaging.TypingTimeout) * time.Second)
+			typingMu.Unlock()
+			return
+		}
+		if !ok {
+			s = &typingState{}
+			typings[key] = s
+		}
+		s.lastAt = now
+		s.expireAt = now.Add(time.Duration(config.Messaging.TypingTimeout) * time.Second)
+	}
+	typingMu.Unlock()
+
+	dispatchTyping(t)
+}
+
+func dispatchTyping(t *message.Typing) {
+	if t.Group {
+		content, err := message.DefaultCodec.Encode(t)
+		if err != nil {
+			logger.E("encode typing error: %v", err)
+			return
+		}
+		msg := message.NewChatMessage(0, 0, t.From, t.To, 0, string(content), time.Now().Unix())
+		err = dispatchTypingMessage(t.To, &msg)
+		if err != nil {
+			logger.D("dispatch group typing error: %v", err)
+		}
+		return
+	}
+	// 对方不在线时直接丢弃
+	_ = client.EnqueueMessage(t.To, message.NewMessage(-1, message.ActionTyping, t))
+}
+
+// expireTypingLoop 定时检查过期的正在输入状态, 为其发送停止事件
+func expireTypingLoop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		var expired []typingKey
+		typingMu.Lock()
+		for key, s := range typings {
+			if now.After(s.expireAt) {
+				expired = append(expired, key)
+				delete(typings, key)
+			}
+		}
+		typingMu.Unlock()
+
+		for _, key := range expired {
+			t := &message.Typing{}
+			t.From = key.from
+			t.To = key.to
+			t.Group = key.group
+			t.Stop = true
+			dispatchTyping(t)
+		}
+	}
+}
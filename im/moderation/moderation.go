@@ -0,0 +1,145 @@
+// Package moderation 消息内容审核, 由多个审核器组成审核流水线, 对用户发送的消息拒绝, 屏蔽或标记待人工审核
+package moderation
+
+import (
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/pkg/logger"
+	"sync"
+)
+
+// Action 命中规则后的处理方式, 数值越大越严格
+type Action int
+
+const (
+	ActionPass Action = iota
+	// ActionFlag 正常发送, 记录待人工审核
+	ActionFlag
+	// ActionMask 屏蔽命中的内容后发送
+	ActionMask
+	// ActionReject 拒绝发送
+	ActionReject
+)
+
+// ParseAction 解析配置和词典中的处理方式
+func ParseAction(s string) (Action, bool) {
+	switch s {
+	case config.ModerationActionReject:
+		return ActionReject, true
+	case config.ModerationActionMask:
+		return ActionMask, true
+	case config.ModerationActionFlag:
+		return ActionFlag, true
+	}
+	return ActionPass, false
+}
+
+// Content 待审核的消息内容
+type Content struct {
+	From  int64
+	To    int64
+	Group bool
+	Type  int32
+	Text  string
+}
+
+// Result 审核结果
+type Result struct {
+	Reject bool
+	Flag   bool
+	// Text 处理后的文本, 未屏蔽时与原文相同
+	Text string
+	// Hits 命中的词或标签
+	Hits []string
+	// Reason 拒绝或标记的原因
+	Reason string
+}
+
+// Moderator 审核器, 敏感词过滤和外部分类服务都实现该接口, 实现需要保证并发安全
+type Moderator interface {
+	Name() string
+	Moderate(c *Content) (*Result, error)
+}
+
+// Pipeline 按顺序执行审核器, 前一个审核器屏蔽后的文本交给下一个审核器, 任一审核器拒绝时立即返回.
+// 审核器出错时跳过该审核器, 不阻塞消息发送.
+type Pipeline struct {
+	mu         sync.RWMutex
+	moderators []Moderator
+}
+
+func NewPipeline(m ...Moderator) *Pipeline {
+	return &Pipeline{moderators: m}
+}
+
+// Use 在流水线末尾添加审核器
+func (p *Pipeline) Use(m ...Moderator) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.moderators = append(p.moderators, m...)
+}
+
+func (p *Pipeline) Name() string {
+	return "pipeline"
+}
+
+func (p *Pipeline) Moderate(c *Content) (*Result, error) {
+	p.mu.RLock()
+	ms := p.moderators
+	p.mu.RUnlock()
+
+	result := &Result{Text: c.Text}
+	content := *c
+	for _, m := range ms {
+		r, err := m.Moderate(&content)
+		if err != nil {
+			logger.E("moderator %s error %v", m.Name(), err)
+			continue
+		}
+		if r == nil {
+			continue
+		}
+		result.Hits = append(result.Hits, r.Hits...)
+		if r.Reject || r.Flag {
+			if result.Reason != "" {
+				result.Reason += "; "
+			}
+			result.Reason += m.Name() + ": " + r.Reason
+		}
+		if r.Reject {
+			result.Reject = true
+			return result, nil
+		}
+		result.Flag = result.Flag || r.Flag
+		result.Text = r.Text
+		content.Text = r.Text
+	}
+	return result, nil
+}
+
+// Default 消息处理使用的审核流水线, 未启用审核时为空流水线
+var Default = NewPipeline()
+
+// Init 根据配置初始化默认审核流水线, 启用时加载敏感词词典并定期检查词典更新
+func Init() error {
+	conf := config.Moderation
+	if !conf.Enable || conf.Dictionary == "" {
+		return nil
+	}
+	defaultAction, ok := ParseAction(conf.DefaultAction)
+	if !ok {
+		defaultAction = ActionMask
+	}
+	mask := '*'
+	if len(conf.MaskChar) > 0 {
+		mask = []rune(conf.MaskChar)[0]
+	}
+	f := NewWordFilter(defaultAction, mask)
+	if err := f.LoadFile(conf.Dictionary); err != nil {
+		return err
+	}
+	if conf.ReloadInterval > 0 {
+		go f.Watch(conf.Dictionary, conf.ReloadInterval)
+	}
+	Default.Use(f)
+	return nil
+}
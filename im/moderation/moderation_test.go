@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDictionary = `
+# 测试词典
+敏感词
+spam,flag
+forbidden,reject
+`
+
+func newTestFilter(t *testing.T) *WordFilter {
+	f := NewWordFilter(ActionMask, '*')
+	if err := f.Load(strings.NewReader(testDictionary)); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestWordFilter_Mask(t *testing.T) {
+	f := newTestFilter(t)
+	r, err := f.Moderate(&Content{Text: "这是敏感词吗"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Reject || r.Flag || r.Text != "这是***吗" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if !reflect.DeepEqual(r.Hits, []string{"敏感词"}) {
+		t.Errorf("unexpected hits %v", r.Hits)
+	}
+}
+
+func TestWordFilter_FlagAndReject(t *testing.T) {
+	f := newTestFilter(t)
+	r, _ := f.Moderate(&Content{Text: "SPAM here"})
+	if !r.Flag || r.Reject || r.Text != "SPAM here" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	r, _ = f.Moderate(&Content{Text: "a Forbidden 敏感词"})
+	if !r.Reject || r.Text != "a Forbidden 敏感词" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	r, _ = f.Moderate(&Content{Text: "hello"})
+	if r.Reject || r.Flag || len(r.Hits) != 0 || r.Text != "hello" {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestWordFilter_Reload(t *testing.T) {
+	f := newTestFilter(t)
+	if err := f.Load(strings.NewReader("hello,reject")); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := f.Moderate(&Content{Text: "hello 敏感词"})
+	if !r.Reject {
+		t.Errorf("expect reject after reload, got %+v", r)
+	}
+	r, _ = f.Moderate(&Content{Text: "敏感词"})
+	if r.Text != "敏感词" {
+		t.Errorf("expect old words removed, got %+v", r)
+	}
+}
+
+func TestPipeline_Moderate(t *testing.T) {
+	stub := &StubModerator{Results: map[string]*Result{
+		"这是***":  {Flag: true, Reason: "classified", Hits: []string{"label"}},
+		"reject": {Reject: true, Reason: "classified"},
+	}}
+	p := NewPipeline(newTestFilter(t), stub)
+
+	r, err := p.Moderate(&Content{Text: "这是敏感词"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Reject || !r.Flag || r.Text != "这是***" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if !reflect.DeepEqual(r.Hits, []string{"敏感词", "label"}) {
+		t.Errorf("unexpected hits %v", r.Hits)
+	}
+
+	r, _ = p.Moderate(&Content{Text: "reject"})
+	if !r.Reject || r.Reason != "stub: classified" {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestPipeline_ModeratorError(t *testing.T) {
+	p := NewPipeline(&StubModerator{Err: errors.New("unavailable")}, newTestFilter(t))
+	r, err := p.Moderate(&Content{Text: "spam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Flag || r.Text != "spam" {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
@@ -0,0 +1,28 @@
+package moderation
+
+// StubModerator 本地审核器桩, 按消息文本返回预设的审核结果, 用于测试和未接入外部分类服务的环境
+type StubModerator struct {
+	// Results 文本对应的审核结果, 未设置的文本通过审核
+	Results map[string]*Result
+	// Err 不为 nil 时审核返回该错误, 用于模拟外部服务不可用
+	Err error
+}
+
+func (s *StubModerator) Name() string {
+	return "stub"
+}
+
+func (s *StubModerator) Moderate(c *Content) (*Result, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	r, ok := s.Results[c.Text]
+	if !ok {
+		return &Result{Text: c.Text}, nil
+	}
+	result := *r
+	if result.Text == "" {
+		result.Text = c.Text
+	}
+	return &result, nil
+}
@@ -0,0 +1,145 @@
+package moderation
+
+import (
+	"bufio"
+	"github.com/glide-im/glideim/pkg/ahocorasick"
+	"github.com/glide-im/glideim/pkg/logger"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type dictionary struct {
+	ac      *ahocorasick.Automaton
+	actions []Action
+}
+
+// WordFilter 敏感词过滤, 使用 Aho-Corasick 自动机一次扫描匹配词典中所有词, 匹配忽略大小写.
+// 词典可以在运行时替换, 替换不影响正在进行的匹配.
+type WordFilter struct {
+	defaultAction Action
+	mask          rune
+	dict          atomic.Value
+}
+
+func NewWordFilter(defaultAction Action, mask rune) *WordFilter {
+	f := &WordFilter{
+		defaultAction: defaultAction,
+		mask:          mask,
+	}
+	f.dict.Store(&dictionary{ac: ahocorasick.New(nil)})
+	return f
+}
+
+func (f *WordFilter) Name() string {
+	return "word_filter"
+}
+
+// Load 从 reader 加载词典并替换当前词典, 每行一个词, 可以用 "词,处理方式" 指定处理方式, # 开头的行和空行忽略
+func (f *WordFilter) Load(r io.Reader) error {
+	var words []string
+	var actions []Action
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word := line
+		action := f.defaultAction
+		if i := strings.LastIndex(line, ","); i > 0 {
+			if a, ok := ParseAction(strings.TrimSpace(line[i+1:])); ok {
+				word = strings.TrimSpace(line[:i])
+				action = a
+			}
+		}
+		words = append(words, word)
+		actions = append(actions, action)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	f.dict.Store(&dictionary{ac: ahocorasick.New(words), actions: actions})
+	return nil
+}
+
+// LoadFile 从文件加载词典
+func (f *WordFilter) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.Load(file)
+}
+
+// Watch 每隔 interval 秒检查词典文件的修改时间, 修改后重新加载, 加载失败时保留原词典, 该方法会阻塞
+func (f *WordFilter) Watch(path string, interval int64) {
+	var lastMod time.Time
+	if stat, err := os.Stat(path); err == nil {
+		lastMod = stat.ModTime()
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		stat, err := os.Stat(path)
+		if err != nil {
+			logger.E("stat moderation dictionary error %v", err)
+			continue
+		}
+		if stat.ModTime().Equal(lastMod) {
+			continue
+		}
+		if err = f.LoadFile(path); err != nil {
+			logger.E("reload moderation dictionary error %v", err)
+			continue
+		}
+		lastMod = stat.ModTime()
+		logger.D("moderation dictionary reloaded, %s", path)
+	}
+}
+
+func (f *WordFilter) Moderate(c *Content) (*Result, error) {
+	dict := f.dict.Load().(*dictionary)
+	result := &Result{Text: c.Text}
+	ms := dict.ac.FindAll(c.Text)
+	if len(ms) == 0 {
+		return result, nil
+	}
+
+	var text []rune
+	hit := map[int]bool{}
+	for _, m := range ms {
+		if !hit[m.Pattern] {
+			hit[m.Pattern] = true
+			result.Hits = append(result.Hits, dict.ac.Pattern(m.Pattern))
+		}
+		switch dict.actions[m.Pattern] {
+		case ActionReject:
+			result.Reject = true
+		case ActionFlag:
+			result.Flag = true
+		case ActionMask:
+			if text == nil {
+				text = []rune(c.Text)
+			}
+			for i := m.Start; i < m.End; i++ {
+				text[i] = f.mask
+			}
+		}
+	}
+	if result.Reject {
+		result.Reason = "sensitive words"
+		result.Text = c.Text
+		return result, nil
+	}
+	if result.Flag {
+		result.Reason = "sensitive words"
+	}
+	if text != nil {
+		result.Text = string(text)
+	}
+	return result, nil
+}
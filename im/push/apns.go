@@ -0,0 +1,132 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"github.com/golang-jwt/jwt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionUrl  = "https://api.push.apple.com"
+	apnsDevelopmentUrl = "https://api.sandbox.push.apple.com"
+	// apnsTokenTTL APNs 认证 token 的有效期需要在 20 到 60 分钟之间
+	apnsTokenTTL = time.Minute * 50
+)
+
+// APNs 以 token 方式认证的 APNs 推送, 使用 HTTP/2 接口
+type APNs struct {
+	endpoint string
+	keyId    string
+	teamId   string
+	topic    string
+	key      *ecdsa.PrivateKey
+	client   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	tokenAt time.Time
+}
+
+func NewAPNs(keyFile string, keyId string, teamId string, topic string, production bool) (*APNs, error) {
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(b)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := apnsDevelopmentUrl
+	if production {
+		endpoint = apnsProductionUrl
+	}
+	return &APNs{
+		endpoint: endpoint,
+		keyId:    keyId,
+		teamId:   teamId,
+		topic:    topic,
+		key:      key,
+		client:   &http.Client{Timeout: time.Second * 10},
+	}, nil
+}
+
+func (a *APNs) Platform() string {
+	return pushdao.PlatformAPNs
+}
+
+func (a *APNs) Send(n *Notification) error {
+	token, err := a.authToken()
+	if err != nil {
+		return err
+	}
+	aps := map[string]interface{}{
+		"alert": map[string]string{
+			"title": n.Title,
+			"body":  n.Body,
+		},
+		"badge":     n.Badge,
+		"thread-id": n.CollapseKey,
+	}
+	if !n.Silent {
+		aps["sound"] = "default"
+	}
+	payload := map[string]interface{}{"aps": aps}
+	for k, v := range n.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, a.endpoint+"/3/device/"+n.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-collapse-id", n.CollapseKey)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	r := struct{ Reason string }{}
+	_ = json.NewDecoder(resp.Body).Decode(&r)
+	if resp.StatusCode == http.StatusGone || r.Reason == "BadDeviceToken" || r.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return errors.New(fmt.Sprintf("apns response %d %s", resp.StatusCode, r.Reason))
+}
+
+// authToken 获取认证 token, 过期前重新签发
+func (a *APNs) authToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && time.Since(a.tokenAt) < apnsTokenTTL {
+		return a.token, nil
+	}
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.StandardClaims{
+		Issuer:   a.teamId,
+		IssuedAt: now.Unix(),
+	})
+	t.Header["kid"] = a.keyId
+	s, err := t.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+	a.token, a.tokenAt = s, now
+	return s, nil
+}
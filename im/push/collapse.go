@@ -0,0 +1,80 @@
+package push
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type collapseState struct {
+	// total 当前合并时间内的消息数, 每次推送合并通知后重新计数
+	total  int
+	latest *Message
+	// pending 最后一次推送后是否有新消息
+	pending bool
+}
+
+// collapser 同一会话的第一条消息立即推送, 合并时间内的后续消息在时间结束时合并为一条通知推送,
+// 通知使用相同的 CollapseKey, 推送平台只展示最新的一条
+type collapser struct {
+	mu     sync.Mutex
+	window time.Duration
+	states map[string]*collapseState
+	send   func(m *Message, count int)
+}
+
+func newCollapser(window time.Duration, send func(m *Message, count int)) *collapser {
+	return &collapser{
+		window: window,
+		states: map[string]*collapseState{},
+		send:   send,
+	}
+}
+
+func (c *collapser) add(m *Message) {
+	if c.window <= 0 {
+		c.send(m, 1)
+		return
+	}
+	key := strconv.FormatInt(m.Uid, 10) + ":" + collapseKey(m)
+	c.mu.Lock()
+	s, ok := c.states[key]
+	if ok {
+		s.total++
+		s.latest = m
+		s.pending = true
+		c.mu.Unlock()
+		return
+	}
+	c.states[key] = &collapseState{total: 1, latest: m}
+	c.mu.Unlock()
+
+	c.send(m, 1)
+	time.AfterFunc(c.window, func() {
+		c.flush(key)
+	})
+}
+
+// flush 合并时间结束, 有新消息时推送合并通知并开始下一个合并时间, 否则结束合并
+func (c *collapser) flush(key string) {
+	c.mu.Lock()
+	s, ok := c.states[key]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	if !s.pending {
+		delete(c.states, key)
+		c.mu.Unlock()
+		return
+	}
+	s.pending = false
+	m, total := s.latest, s.total
+	s.total = 0
+	c.mu.Unlock()
+
+	c.send(m, total)
+	time.AfterFunc(c.window, func() {
+		c.flush(key)
+	})
+}
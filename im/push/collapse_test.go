@@ -0,0 +1,75 @@
+package push
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCollapser(t *testing.T) {
+	var mu sync.Mutex
+	var counts []int
+	var last []int64
+	c := newCollapser(time.Millisecond*50, func(m *Message, count int) {
+		mu.Lock()
+		counts = append(counts, count)
+		last = append(last, m.Mid)
+		mu.Unlock()
+	})
+
+	for i := int64(1); i <= 5; i++ {
+		c.add(&Message{Uid: 1, From: 2, Mid: i})
+	}
+	// 其他会话不合并
+	c.add(&Message{Uid: 1, Gid: 3, Mid: 10})
+	time.Sleep(time.Millisecond * 200)
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectCounts := []int{1, 1, 5}
+	expectMids := []int64{1, 10, 5}
+	if len(counts) != len(expectCounts) {
+		t.Fatalf("expect %v, got %v", expectCounts, counts)
+	}
+	for i := range counts {
+		if counts[i] != expectCounts[i] || last[i] != expectMids[i] {
+			t.Errorf("expect %v %v, got %v %v", expectCounts, expectMids, counts, last)
+			break
+		}
+	}
+	c.mu.Lock()
+	if len(c.states) != 0 {
+		t.Errorf("expect states cleared, got %d", len(c.states))
+	}
+	c.mu.Unlock()
+}
+
+func TestCollapser_ResetPerWindow(t *testing.T) {
+	var mu sync.Mutex
+	var counts []int
+	c := newCollapser(time.Millisecond*100, func(m *Message, count int) {
+		mu.Lock()
+		counts = append(counts, count)
+		mu.Unlock()
+	})
+
+	c.add(&Message{Uid: 1, From: 2, Mid: 1})
+	c.add(&Message{Uid: 1, From: 2, Mid: 2})
+	c.add(&Message{Uid: 1, From: 2, Mid: 3})
+	// 第一次合并通知后的下一个合并时间内
+	time.Sleep(time.Millisecond * 150)
+	c.add(&Message{Uid: 1, From: 2, Mid: 4})
+	time.Sleep(time.Millisecond * 250)
+
+	mu.Lock()
+	defer mu.Unlock()
+	expect := []int{1, 3, 1}
+	if len(counts) != len(expect) {
+		t.Fatalf("expect %v, got %v", expect, counts)
+	}
+	for i := range counts {
+		if counts[i] != expect[i] {
+			t.Fatalf("expect %v, got %v", expect, counts)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"net/http"
+	"time"
+)
+
+const fcmUrl = "https://fcm.googleapis.com/fcm/send"
+
+// FCM 以服务端密钥认证的 FCM HTTP 推送
+type FCM struct {
+	endpoint  string
+	serverKey string
+	client    *http.Client
+}
+
+func NewFCM(serverKey string) *FCM {
+	return &FCM{
+		endpoint:  fcmUrl,
+		serverKey: serverKey,
+		client:    &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+func (f *FCM) Platform() string {
+	return pushdao.PlatformFCM
+}
+
+func (f *FCM) Send(n *Notification) error {
+	notification := map[string]interface{}{
+		"title": n.Title,
+		"body":  n.Body,
+		"tag":   n.CollapseKey,
+		"badge": n.Badge,
+	}
+	if !n.Silent {
+		notification["sound"] = "default"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"to":           n.Token,
+		"collapse_key": n.CollapseKey,
+		"priority":     "high",
+		"notification": notification,
+		"data":         n.Data,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, f.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "key="+f.serverKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(fmt.Sprintf("fcm response %d", resp.StatusCode))
+	}
+	r := struct {
+		Failure int
+		Results []struct {
+			Error string
+		}
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	if r.Failure == 0 || len(r.Results) == 0 {
+		return nil
+	}
+	switch e := r.Results[0].Error; e {
+	case "":
+		return nil
+	case "NotRegistered", "InvalidRegistration":
+		return ErrInvalidToken
+	default:
+		return errors.New("fcm error " + e)
+	}
+}
@@ -0,0 +1,41 @@
+package push
+
+import "sync"
+
+// MockProvider 记录推送的通知而不实际推送, 用于测试和本地开发
+type MockProvider struct {
+	Name string
+	// InvalidTokens 这些 token 推送时返回 ErrInvalidToken
+	InvalidTokens map[string]bool
+
+	mu   sync.Mutex
+	sent []*Notification
+}
+
+func NewMockProvider(platform string) *MockProvider {
+	return &MockProvider{
+		Name:          platform,
+		InvalidTokens: map[string]bool{},
+	}
+}
+
+func (m *MockProvider) Platform() string {
+	return m.Name
+}
+
+func (m *MockProvider) Send(n *Notification) error {
+	if m.InvalidTokens[n.Token] {
+		return ErrInvalidToken
+	}
+	m.mu.Lock()
+	m.sent = append(m.sent, n)
+	m.mu.Unlock()
+	return nil
+}
+
+// Sent 返回已推送的通知
+func (m *MockProvider) Sent() []*Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Notification{}, m.sent...)
+}
@@ -0,0 +1,144 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/glide-im/glideim/config"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPNs_Send(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var path, auth, collapseId string
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth, collapseId = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("apns-collapse-id")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+		}
+	}))
+	defer srv.Close()
+
+	a := &APNs{endpoint: srv.URL, keyId: "k", teamId: "t", topic: "im.glide", key: key, client: srv.Client()}
+	err := a.Send(&Notification{Token: "abc", Title: "bob", Body: "hi", Badge: 3, CollapseKey: "c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/3/device/abc" || !strings.HasPrefix(auth, "bearer ") || collapseId != "c1" {
+		t.Errorf("unexpected request %s %s %s", path, auth, collapseId)
+	}
+	aps := payload["aps"].(map[string]interface{})
+	if aps["badge"].(float64) != 3 || aps["sound"] != "default" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+	if err = a.Send(&Notification{Token: "gone"}); err != ErrInvalidToken {
+		t.Errorf("expect %v, got %v", ErrInvalidToken, err)
+	}
+}
+
+func TestFCM_Send(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		b, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(b), `"to":"bad"`) {
+			_, _ = w.Write([]byte(`{"failure":1,"results":[{"error":"NotRegistered"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":1,"results":[{"message_id":"1"}]}`))
+	}))
+	defer srv.Close()
+
+	f := NewFCM("server-key")
+	f.endpoint = srv.URL
+	if err := f.Send(&Notification{Token: "ok", Title: "bob", Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "key=server-key" {
+		t.Errorf("unexpected auth %s", auth)
+	}
+	if err := f.Send(&Notification{Token: "bad"}); err != ErrInvalidToken {
+		t.Errorf("expect %v, got %v", ErrInvalidToken, err)
+	}
+}
+
+func TestWebPush_Send(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	priv := base64.RawURLEncoding.EncodeToString(key.D.Bytes())
+	pub := base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+
+	defer func(hosts []string) { config.Push.WebPushHosts = hosts }(config.Push.WebPushHosts)
+	config.Push.WebPushHosts = []string{".example.com"}
+
+	var auth, urgency string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, urgency = r.Header.Get("Authorization"), r.Header.Get("Urgency")
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	wp, err := NewWebPush(pub, priv, "mailto:admin@glide-im.pro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wp.key.X.Cmp(key.X) != 0 {
+		t.Fatal("public key mismatch")
+	}
+	// 推送服务域名解析到测试服务器
+	wp.client = srv.Client()
+	wp.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{Timeout: time.Second}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	err = wp.Send(&Notification{Token: `{"endpoint":"https://push.example.com/sub"}`, CollapseKey: "c1", Silent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(auth, "vapid t=") || !strings.HasSuffix(auth, "k="+pub) || urgency != "low" {
+		t.Errorf("unexpected request %s %s", auth, urgency)
+	}
+	if err = wp.Send(&Notification{Token: `{"endpoint":"https://push.example.com/gone"}`}); err != ErrInvalidToken {
+		t.Errorf("expect %v, got %v", ErrInvalidToken, err)
+	}
+	for _, token := range []string{
+		"not json",
+		`{"endpoint":"` + srv.URL + `/sub"}`,
+		`{"endpoint":"http://push.example.com/sub"}`,
+		`{"endpoint":"https://push.example.com:8443/sub"}`,
+		`{"endpoint":"https://example.com.evil.io/sub"}`,
+	} {
+		if ValidWebPushToken(token) {
+			t.Errorf("expect invalid token %s", token)
+		}
+		if err = wp.Send(&Notification{Token: token}); err != ErrInvalidToken {
+			t.Errorf("expect %v, got %v", ErrInvalidToken, err)
+		}
+	}
+}
+
+func TestMockProvider(t *testing.T) {
+	m := NewMockProvider("mock")
+	m.InvalidTokens["bad"] = true
+	if err := m.Send(&Notification{Token: "bad"}); err != ErrInvalidToken {
+		t.Errorf("expect %v, got %v", ErrInvalidToken, err)
+	}
+	_ = m.Send(&Notification{Token: "ok", Body: "hi"})
+	if s := m.Sent(); len(s) != 1 || s[0].Body != "hi" {
+		t.Errorf("unexpected sent %v", s)
+	}
+}
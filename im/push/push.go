@@ -0,0 +1,238 @@
+// Package push 向离线用户的设备推送通知, 支持 APNs, FCM 和 Web Push,
+// 通知内容按消息类型模板生成, 角标为会话未读数, 遵循会话免打扰和免打扰时段, 同一会话短时间内的多条消息合并为一条通知
+package push
+
+import (
+	"errors"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/groupdao"
+	"github.com/glide-im/glideim/im/dao/msgdao"
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"github.com/glide-im/glideim/im/dao/userdao"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/panjf2000/ants/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken 推送平台报告 token 失效, 失效的 token 会被删除
+var ErrInvalidToken = errors.New("invalid push token")
+
+// Notification 推送给一个设备的通知
+type Notification struct {
+	Token string
+	Title string
+	Body  string
+	Badge int64
+	// CollapseKey 同一会话的通知使用相同的 key, 推送平台只展示最新的一条
+	CollapseKey string
+	// Silent 免打扰时段内的通知不响铃
+	Silent bool
+	Data   map[string]string
+}
+
+// Provider 推送平台
+type Provider interface {
+	// Platform 平台名称, 与设备 token 的平台对应
+	Platform() string
+	Send(n *Notification) error
+}
+
+// Message 需要推送给离线用户的消息
+type Message struct {
+	// Uid 接收者
+	Uid  int64
+	From int64
+	// Gid 群消息的群 ID, 单聊为 0
+	Gid     int64
+	Mid     int64
+	Type    int32
+	Content string
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+
+	execPool *ants.Pool
+	collapse *collapser
+)
+
+func init() {
+	var err error
+	execPool, err = ants.NewPool(1000,
+		ants.WithNonblocking(true),
+		ants.WithPreAlloc(false),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Register 注册推送平台, 同一平台后注册的替换先注册的
+func Register(p Provider) {
+	mu.Lock()
+	providers[p.Platform()] = p
+	mu.Unlock()
+}
+
+func getProvider(platform string) Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	return providers[platform]
+}
+
+func hasProvider() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(providers) > 0
+}
+
+// Init 加载通知模板并注册已配置的推送平台, 未启用推送时不加载
+func Init() error {
+	if !config.Push.Enable {
+		return nil
+	}
+	c := config.Push
+	loadTemplates(c.Templates, c.CollapseTemplate)
+	collapse = newCollapser(time.Duration(c.CollapseWindow)*time.Second, submit)
+
+	if c.ApnsKeyFile != "" {
+		p, err := NewAPNs(c.ApnsKeyFile, c.ApnsKeyId, c.ApnsTeamId, c.ApnsTopic, c.ApnsProduction)
+		if err != nil {
+			return err
+		}
+		Register(p)
+	}
+	if c.FcmServerKey != "" {
+		Register(NewFCM(c.FcmServerKey))
+	}
+	if c.WebPushPrivateKey != "" {
+		p, err := NewWebPush(c.WebPushPublicKey, c.WebPushPrivateKey, c.WebPushSubject)
+		if err != nil {
+			return err
+		}
+		Register(p)
+	}
+	return nil
+}
+
+// Enabled 是否启用并配置了推送平台
+func Enabled() bool {
+	return config.Push.Enable && collapse != nil && hasProvider()
+}
+
+// Notify 向离线用户推送消息通知, 同一会话在合并时间内的后续消息合并为一条通知, 推送异步执行
+func Notify(m *Message) {
+	if !Enabled() {
+		return
+	}
+	collapse.add(m)
+}
+
+func submit(m *Message, count int) {
+	err := execPool.Submit(func() {
+		deliver(m, count)
+	})
+	if err != nil {
+		logger.E("submit push notification error %v", err)
+	}
+}
+
+func deliver(m *Message, count int) {
+	target, group := m.From, false
+	if m.Gid != 0 {
+		target, group = m.Gid, true
+	}
+	muted, err := pushdao.Dao.IsMuted(m.Uid, target, group)
+	if err != nil {
+		logger.E("get push mute error %v", err)
+		return
+	}
+	if muted {
+		return
+	}
+	tokens, err := pushdao.Dao.GetTokens(m.Uid)
+	if err != nil {
+		logger.E("get push tokens error %v", err)
+		return
+	}
+	if len(tokens) == 0 {
+		return
+	}
+	setting, err := pushdao.Dao.GetSetting(m.Uid)
+	if err != nil {
+		logger.E("get push setting error %v", err)
+		return
+	}
+	badge, err := msgdao.SessionDaoImpl.GetUnreadCount(m.Uid, config.Push.BadgeSessions)
+	if err != nil {
+		logger.E("get unread count error %v", err)
+	}
+
+	data := &templateData{Type: m.Type, Content: m.Content, Count: count}
+	if u, err := userdao.UserInfoDao.GetUser(m.From); err == nil {
+		data.Sender = u.Nickname
+	}
+	title := data.Sender
+	if group {
+		if g, err := groupdao.Dao.GetGroup(m.Gid); err == nil {
+			data.Group = g.Name
+			title = g.Name
+		}
+	}
+	body := render(data, setting.HidePreview)
+	silent := inDnd(setting, time.Now())
+
+	for _, t := range tokens {
+		p := getProvider(t.Platform)
+		if p == nil {
+			continue
+		}
+		n := &Notification{
+			Token:       t.Token,
+			Title:       title,
+			Body:        body,
+			Badge:       badge,
+			CollapseKey: collapseKey(m),
+			Silent:      silent,
+			Data: map[string]string{
+				"mid":  strconv.FormatInt(m.Mid, 10),
+				"from": strconv.FormatInt(m.From, 10),
+				"gid":  strconv.FormatInt(m.Gid, 10),
+			},
+		}
+		err = p.Send(n)
+		if err == ErrInvalidToken {
+			if err = pushdao.Dao.RemoveTokenByValue(t.Platform, t.Token); err != nil {
+				logger.E("remove invalid push token error %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			logger.E("push notification to %s error %v", t.Platform, err)
+		}
+	}
+}
+
+// inDnd 当前时间是否在用户的免打扰时段内
+func inDnd(s *pushdao.PushSetting, now time.Time) bool {
+	if s.DndStart == s.DndEnd {
+		return false
+	}
+	t := now.UTC().Add(time.Duration(s.TzOffset) * time.Minute)
+	m := t.Hour()*60 + t.Minute()
+	if s.DndStart < s.DndEnd {
+		return m >= s.DndStart && m < s.DndEnd
+	}
+	return m >= s.DndStart || m < s.DndEnd
+}
+
+// collapseKey 接收者和会话相同的消息使用相同的 key
+func collapseKey(m *Message) string {
+	if m.Gid != 0 {
+		return "g" + strconv.FormatInt(m.Gid, 10)
+	}
+	return "c" + strconv.FormatInt(m.From, 10)
+}
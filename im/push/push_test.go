@@ -0,0 +1,59 @@
+package push
+
+import (
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"testing"
+	"time"
+)
+
+func TestInDnd(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2022, 1, 1, h, m, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		name    string
+		setting pushdao.PushSetting
+		now     time.Time
+		want    bool
+	}{
+		{"disabled", pushdao.PushSetting{}, at(3, 0), false},
+		{"same day inside", pushdao.PushSetting{DndStart: 12 * 60, DndEnd: 14 * 60}, at(13, 0), true},
+		{"same day end", pushdao.PushSetting{DndStart: 12 * 60, DndEnd: 14 * 60}, at(14, 0), false},
+		{"overnight before midnight", pushdao.PushSetting{DndStart: 22 * 60, DndEnd: 7 * 60}, at(23, 30), true},
+		{"overnight after midnight", pushdao.PushSetting{DndStart: 22 * 60, DndEnd: 7 * 60}, at(6, 59), true},
+		{"overnight outside", pushdao.PushSetting{DndStart: 22 * 60, DndEnd: 7 * 60}, at(12, 0), false},
+		{"timezone", pushdao.PushSetting{DndStart: 22 * 60, DndEnd: 7 * 60, TzOffset: 8 * 60}, at(15, 0), true},
+	}
+	for _, c := range cases {
+		if got := inDnd(&c.setting, c.now); got != c.want {
+			t.Errorf("%s: expect %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	loadTemplates(map[string]string{
+		"default": "{{.Sender}}: {{.Content}}",
+		"2":       "{{.Sender}}: [图片]",
+		"3":       "{{.Broken",
+	}, "{{.Count}} 条新消息")
+
+	cases := []struct {
+		name string
+		data templateData
+		hide bool
+		want string
+	}{
+		{"default", templateData{Type: 1, Content: "hi", Sender: "bob", Count: 1}, false, "bob: hi"},
+		{"typed", templateData{Type: 2, Content: "http://img", Sender: "bob", Count: 1}, false, "bob: [图片]"},
+		{"broken template uses default", templateData{Type: 3, Content: "hi", Sender: "bob", Count: 1}, false, "bob: hi"},
+		{"collapsed", templateData{Type: 1, Content: "hi", Count: 5}, false, "5 条新消息"},
+		{"hidden", templateData{Type: 1, Content: "hi", Count: 1}, true, hiddenBody},
+		{"hidden collapsed", templateData{Type: 1, Content: "hi", Count: 3}, true, "3 条新消息"},
+	}
+	for _, c := range cases {
+		if got := render(&c.data, c.hide); got != c.want {
+			t.Errorf("%s: expect %q, got %q", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package push
+
+import (
+	"github.com/glide-im/glideim/pkg/logger"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	templateDefault = "default"
+	// hiddenBody 用户关闭消息预览时的通知内容
+	hiddenBody = "你收到一条新消息"
+)
+
+type templateData struct {
+	Type    int32
+	Content string
+	Sender  string
+	Group   string
+	// Count 合并的消息数量
+	Count int
+}
+
+var (
+	tplMu            sync.RWMutex
+	templates        = map[string]*template.Template{}
+	collapseTemplate *template.Template
+)
+
+// loadTemplates 解析通知模板, 解析失败的模板忽略, 该类型使用默认模板
+func loadTemplates(tpls map[string]string, collapse string) {
+	ts := map[string]*template.Template{}
+	for typ, text := range tpls {
+		t, err := template.New(typ).Parse(text)
+		if err != nil {
+			logger.E("parse push template %s error %v", typ, err)
+			continue
+		}
+		ts[strings.ToLower(typ)] = t
+	}
+	c, err := template.New("collapse").Parse(collapse)
+	if err != nil {
+		logger.E("parse push collapse template error %v", err)
+		c = nil
+	}
+	tplMu.Lock()
+	templates = ts
+	collapseTemplate = c
+	tplMu.Unlock()
+}
+
+// render 生成通知内容, 多条消息合并时使用合并模板
+func render(d *templateData, hidePreview bool) string {
+	tplMu.RLock()
+	t := templates[strconv.FormatInt(int64(d.Type), 10)]
+	if t == nil {
+		t = templates[templateDefault]
+	}
+	if d.Count > 1 {
+		t = collapseTemplate
+	}
+	tplMu.RUnlock()
+
+	if hidePreview && d.Count <= 1 {
+		return hiddenBody
+	}
+	if t == nil {
+		if d.Count > 1 {
+			return strconv.Itoa(d.Count) + " 条新消息"
+		}
+		return d.Content
+	}
+	sb := strings.Builder{}
+	if err := t.Execute(&sb, d); err != nil {
+		logger.E("execute push template error %v", err)
+		return d.Content
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package push
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/pushdao"
+	"github.com/glide-im/glideim/pkg/netguard"
+	"github.com/golang-jwt/jwt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// webPushTTL 推送服务保存离线通知的时间, 单位秒
+const webPushTTL = "86400"
+
+// WebPush 以 VAPID 认证的 Web Push, 不携带加密的消息内容, 浏览器的 Service Worker 收到推送后从服务端同步消息.
+// 设备 token 为浏览器 PushSubscription 的 json
+type WebPush struct {
+	publicKey string
+	key       *ecdsa.PrivateKey
+	subject   string
+	client    *http.Client
+}
+
+func NewWebPush(publicKey string, privateKey string, subject string) (*WebPush, error) {
+	d, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d)
+	return &WebPush{
+		publicKey: publicKey,
+		key:       key,
+		subject:   subject,
+		client:    netguard.NewClient(time.Second * 10),
+	}, nil
+}
+
+func (w *WebPush) Platform() string {
+	return pushdao.PlatformWebPush
+}
+
+// parseWebPushEndpoint 解析 PushSubscription 中的推送地址, 只接受 https 和配置中允许的推送服务域名
+func parseWebPushEndpoint(token string) (*url.URL, error) {
+	sub := struct{ Endpoint string }{}
+	if err := json.Unmarshal([]byte(token), &sub); err != nil || sub.Endpoint == "" {
+		return nil, ErrInvalidToken
+	}
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return nil, ErrInvalidToken
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range config.Push.WebPushHosts {
+		h = strings.ToLower(h)
+		if host == h || (strings.HasPrefix(h, ".") && strings.HasSuffix(host, h)) {
+			return u, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// ValidWebPushToken 检查 Web Push 的设备 token 是否为允许的推送服务地址
+func ValidWebPushToken(token string) bool {
+	_, err := parseWebPushEndpoint(token)
+	return err == nil
+}
+
+func (w *WebPush) Send(n *Notification) error {
+	u, err := parseWebPushEndpoint(n.Token)
+	if err != nil {
+		return err
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(time.Hour * 12).Unix(),
+		"sub": w.subject,
+	})
+	token, err := t.SignedString(w.key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+w.publicKey)
+	req.Header.Set("TTL", webPushTTL)
+	req.Header.Set("Topic", n.CollapseKey)
+	if n.Silent {
+		req.Header.Set("Urgency", "low")
+	} else {
+		req.Header.Set("Urgency", "high")
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound, http.StatusGone:
+		return ErrInvalidToken
+	default:
+		return errors.New(fmt.Sprintf("web push response %d", resp.StatusCode))
+	}
+}
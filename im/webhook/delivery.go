@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/webhookdao"
+	"github.com/glide-im/glideim/pkg/logger"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Glide-Event"
+	HeaderEventId   = "X-Glide-Event-Id"
+	HeaderDelivery  = "X-Glide-Delivery"
+	HeaderTimestamp = "X-Glide-Timestamp"
+	HeaderSignature = "X-Glide-Signature"
+
+	// retryBatch 每次检查最多取出的到期推送数量
+	retryBatch = 200
+	// maxErrorLen 保存的错误信息的最大长度
+	maxErrorLen = 255
+)
+
+var (
+	httpClient = &http.Client{}
+	// guardedClient 禁止访问内网的客户端, 用于机器人的订阅
+	guardedClient = &http.Client{}
+)
+
+// Sign 计算推送签名, 签名内容为 "时间戳.请求体", 接收方应使用订阅密钥以相同方式计算并比较 X-Glide-Signature
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff 第 attempts 次推送失败后到下次推送的等待时间, 单位秒
+func backoff(attempts int, base int64, max int64) int64 {
+	wait := base
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
+
+// leaseTime 推送中的记录的租约时间, 租约到期仍未完成时由重试任务重新推送, 单位秒
+func leaseTime() int64 {
+	return config.Webhook.Timeout + 30
+}
+
+func retryDueDeliveries() {
+	now := time.Now().Unix()
+	ds, err := webhookdao.Dao.GetDueDeliveries(now, retryBatch)
+	if err != nil {
+		logger.E("load due webhook deliveries error %v", err)
+		return
+	}
+	for _, d := range ds {
+		until := now + leaseTime()
+		ok, err := webhookdao.Dao.ClaimDelivery(d.ID, d.NextAttemptAt, until)
+		if err != nil {
+			logger.E("claim webhook delivery error %v", err)
+			continue
+		}
+		if !ok {
+			// 其他节点已经开始推送
+			continue
+		}
+		d.NextAttemptAt = until
+		submitAttempt(d)
+	}
+}
+
+func submitAttempt(d *webhookdao.WebhookDelivery) {
+	err := execPool.Submit(func() {
+		attempt(d)
+	})
+	if err != nil {
+		// 租约到期后由重试任务推送
+		logger.E("submit webhook delivery error %v", err)
+	}
+}
+
+// attempt 推送一次并记录结果, 失败时按退避时间安排重试, 次数用尽或订阅已停用时进入死信列表
+func attempt(d *webhookdao.WebhookDelivery) {
+	s := getSubscription(d.WebhookID)
+	if s == nil {
+		d.Status = webhookdao.DeliveryStatusDead
+		d.LastError = "webhook disabled or removed"
+		if err := webhookdao.Dao.UpdateDelivery(d); err != nil {
+			logger.E("update webhook delivery error %v", err)
+		}
+		return
+	}
+
+	d.Attempts++
+	start := time.Now()
+	code, err := post(s.client, s.url, s.secret, d)
+	a := &webhookdao.WebhookAttempt{
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts,
+		StatusCode: code,
+		Duration:   time.Since(start).Milliseconds(),
+		CreateAt:   start.Unix(),
+	}
+	if err != nil {
+		a.Error = truncate(err.Error())
+	}
+	if e := webhookdao.Dao.AddAttempt(a); e != nil {
+		logger.E("save webhook attempt error %v", e)
+	}
+
+	if err == nil {
+		d.Status = webhookdao.DeliveryStatusSuccess
+		d.LastError = ""
+	} else {
+		d.LastError = a.Error
+		if d.Attempts >= config.Webhook.MaxAttempts {
+			d.Status = webhookdao.DeliveryStatusDead
+			logger.W("webhook delivery %d dead after %d attempts, %v", d.ID, d.Attempts, err)
+		} else {
+			wait := backoff(d.Attempts, config.Webhook.RetryBackoff, config.Webhook.MaxRetryBackoff)
+			d.NextAttemptAt = time.Now().Unix() + wait
+		}
+	}
+	if err = webhookdao.Dao.UpdateDelivery(d); err != nil {
+		logger.E("update webhook delivery error %v", err)
+	}
+}
+
+// post 发送推送请求, 返回响应状态码, 非 2xx 响应视为失败
+func post(client *http.Client, url string, secret string, d *webhookdao.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderEventId, d.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 读取响应以复用连接
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) > maxErrorLen {
+		return string(r[:maxErrorLen])
+	}
+	return s
+}
@@ -0,0 +1,309 @@
+// Package webhook 将消息, 群成员变动, 登录等事件推送给订阅的外部服务, 推送记录保存在数据库中, 失败后按指数退避重试
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/glide-im/glideim/config"
+	"github.com/glide-im/glideim/im/dao/webhookdao"
+	"github.com/glide-im/glideim/pkg/logger"
+	"github.com/glide-im/glideim/pkg/netguard"
+	"github.com/panjf2000/ants/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	EventChatMessage        = "message.chat"
+	EventChatRecall         = "message.chat.recall"
+	EventChatEdit           = "message.chat.edit"
+	EventGroupMessage       = "message.group"
+	EventGroupRecall        = "message.group.recall"
+	EventGroupEdit          = "message.group.edit"
+	EventGroupCreated       = "group.created"
+	EventGroupMemberAdded   = "group.member.added"
+	EventGroupMemberRemoved = "group.member.removed"
+	EventUserRegistered     = "user.registered"
+	EventUserSignIn         = "user.signin"
+	EventUserSignOut        = "user.signout"
+
+	// EventAll 订阅所有事件
+	EventAll = "*"
+)
+
+// Events 所有可以订阅的事件
+var Events = []string{
+	EventChatMessage, EventChatRecall, EventChatEdit,
+	EventGroupMessage, EventGroupRecall, EventGroupEdit,
+	EventGroupCreated, EventGroupMemberAdded, EventGroupMemberRemoved,
+	EventUserRegistered, EventUserSignIn, EventUserSignOut,
+}
+
+// GroupEvent 群创建和群成员变动事件数据
+type GroupEvent struct {
+	Gid int64
+	Uid int64
+}
+
+// UserEvent 用户注册, 登录和登出事件数据
+type UserEvent struct {
+	Uid    int64
+	Device int64
+}
+
+// Payload 推送的请求体
+type Payload struct {
+	// Id 事件 ID, 同一事件推送给不同订阅时相同
+	Id       string
+	Event    string
+	CreateAt int64
+	Data     interface{}
+}
+
+type subscription struct {
+	id     int64
+	url    string
+	secret string
+	all    bool
+	events map[string]bool
+	// client 推送使用的客户端, 机器人的订阅始终使用禁止访问内网的客户端
+	client *http.Client
+}
+
+func (s *subscription) match(event string) bool {
+	return s.all || s.events[event]
+}
+
+var (
+	mu            sync.RWMutex
+	subscriptions = map[int64]*subscription{}
+
+	// lastMissReload 推送给指定订阅时未找到订阅而重新加载的时间, 限制重新加载的频率
+	lastMissReload int64
+
+	execPool *ants.Pool
+)
+
+func init() {
+	var err error
+	execPool, err = ants.NewPool(1000,
+		ants.WithNonblocking(true),
+		ants.WithPreAlloc(false),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ValidEvent 是否为可以订阅的事件
+func ValidEvent(event string) bool {
+	if event == EventAll {
+		return true
+	}
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Init 加载已启用的订阅, 未启用推送时不加载
+func Init() error {
+	if !config.Webhook.Enable {
+		return nil
+	}
+	timeout := time.Duration(config.Webhook.Timeout) * time.Second
+	guardedClient = netguard.NewClient(timeout)
+	if config.Webhook.AllowPrivateNetwork {
+		httpClient = &http.Client{Timeout: timeout}
+	} else {
+		httpClient = guardedClient
+	}
+	return Reload()
+}
+
+// Reload 从数据库重新加载已启用的订阅, 修改订阅后调用, 其他节点在刷新间隔后生效
+func Reload() error {
+	ws, err := webhookdao.Dao.GetWebhooks(true)
+	if err != nil {
+		return err
+	}
+	subs := map[int64]*subscription{}
+	for _, w := range ws {
+		s := &subscription{
+			id:     w.ID,
+			url:    w.Url,
+			secret: w.Secret,
+			events: map[string]bool{},
+			client: httpClient,
+		}
+		if w.Bot {
+			s.client = guardedClient
+		}
+		for _, e := range strings.Split(w.Events, ",") {
+			e = strings.TrimSpace(e)
+			if e == EventAll {
+				s.all = true
+			}
+			s.events[e] = true
+		}
+		subs[w.ID] = s
+	}
+	mu.Lock()
+	subscriptions = subs
+	mu.Unlock()
+	return nil
+}
+
+func getSubscription(id int64) *subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	return subscriptions[id]
+}
+
+func matchSubscriptions(event string) []int64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	var ids []int64
+	for id, s := range subscriptions {
+		if s.match(event) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Publish 向订阅了 event 的所有订阅推送事件, 事件数据在调用时编码, 保存和推送异步执行, 不阻塞调用者
+func Publish(event string, data interface{}) {
+	if !config.Webhook.Enable {
+		return
+	}
+	ids := matchSubscriptions(event)
+	if len(ids) == 0 {
+		return
+	}
+	publishTo(ids, event, data)
+}
+
+// PublishTo 向指定订阅推送事件, 不检查订阅的事件, 用于推送给机器人等不按事件订阅的接收者,
+// 未启用推送或订阅不存在时返回 false, 由调用者改用其他方式投递
+func PublishTo(id int64, event string, data interface{}) bool {
+	if !config.Webhook.Enable {
+		return false
+	}
+	if getSubscription(id) == nil && !reloadOnMiss(id) {
+		logger.W("webhook %d not exist or disabled, event %s not published", id, event)
+		return false
+	}
+	publishTo([]int64{id}, event, data)
+	return true
+}
+
+// reloadOnMiss 订阅可能由其他节点新建而尚未刷新, 重新加载后再查找, 每秒最多重新加载一次
+func reloadOnMiss(id int64) bool {
+	now := time.Now().Unix()
+	last := atomic.LoadInt64(&lastMissReload)
+	if now == last || !atomic.CompareAndSwapInt64(&lastMissReload, last, now) {
+		return false
+	}
+	if err := Reload(); err != nil {
+		logger.E("reload webhook subscriptions error %v", err)
+		return false
+	}
+	return getSubscription(id) != nil
+}
+
+func publishTo(ids []int64, event string, data interface{}) {
+	now := time.Now().Unix()
+	eventId := newEventId()
+	body, err := json.Marshal(&Payload{
+		Id:       eventId,
+		Event:    event,
+		CreateAt: now,
+		Data:     data,
+	})
+	if err != nil {
+		logger.E("encode webhook payload error %v", err)
+		return
+	}
+	err = execPool.Submit(func() {
+		publish(event, eventId, body, now, ids)
+	})
+	if err != nil {
+		logger.E("publish webhook event %s error %v", event, err)
+	}
+}
+
+func publish(event string, eventId string, body []byte, now int64, ids []int64) {
+	var ds []*webhookdao.WebhookDelivery
+	for _, id := range ids {
+		ds = append(ds, &webhookdao.WebhookDelivery{
+			WebhookID: id,
+			EventID:   eventId,
+			Event:     event,
+			Payload:   string(body),
+			Status:    webhookdao.DeliveryStatusPending,
+			// 先以租约到期时间保存, 立即推送, 节点在推送完成前退出时由重试任务在租约到期后推送
+			NextAttemptAt: now + leaseTime(),
+			CreateAt:      now,
+			UpdateAt:      now,
+		})
+	}
+	err := webhookdao.Dao.AddDeliveries(ds)
+	if err != nil {
+		logger.E("save webhook deliveries error %v", err)
+		return
+	}
+	for _, d := range ds {
+		submitAttempt(d)
+	}
+}
+
+// Redeliver 重新推送死信列表或已完成的推送, 推送次数重新计算, 由重试任务推送
+func Redeliver(id int64) (*webhookdao.WebhookDelivery, error) {
+	d, err := webhookdao.Dao.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	d.Status = webhookdao.DeliveryStatusPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now().Unix()
+	d.LastError = ""
+	err = webhookdao.Dao.UpdateDelivery(d)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Run 定期推送到期的重试并刷新订阅, 未启用推送时直接返回, 该方法会阻塞
+func Run() {
+	if !config.Webhook.Enable {
+		return
+	}
+	check := time.NewTicker(time.Duration(config.Webhook.CheckInterval) * time.Second)
+	refresh := time.NewTicker(time.Duration(config.Webhook.RefreshInterval) * time.Second)
+	defer check.Stop()
+	defer refresh.Stop()
+	for {
+		select {
+		case <-check.C:
+			retryDueDeliveries()
+		case <-refresh.C:
+			if err := Reload(); err != nil {
+				logger.E("reload webhook subscriptions error %v", err)
+			}
+		}
+	}
+}
+
+func newEventId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
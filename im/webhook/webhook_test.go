@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"github.com/glide-im/glideim/im/dao/webhookdao"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	s1 := Sign("secret", 1600000000, []byte(`{"Event":"message.chat"}`))
+	s2 := Sign("secret", 1600000000, []byte(`{"Event":"message.chat"}`))
+	if s1 != s2 {
+		t.Error("expect same signature for same input")
+	}
+	if s1 == Sign("secret", 1600000001, []byte(`{"Event":"message.chat"}`)) {
+		t.Error("expect timestamp covered by signature")
+	}
+	if s1 == Sign("other", 1600000000, []byte(`{"Event":"message.chat"}`)) {
+		t.Error("expect secret covered by signature")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	expect := []int64{10, 20, 40, 80, 100, 100}
+	for i, e := range expect {
+		if b := backoff(i+1, 10, 100); b != e {
+			t.Errorf("attempt %d expect backoff %d, got %d", i+1, e, b)
+		}
+	}
+}
+
+func TestSubscription_Match(t *testing.T) {
+	s := &subscription{events: map[string]bool{EventChatMessage: true}}
+	if !s.match(EventChatMessage) || s.match(EventGroupMessage) {
+		t.Error("unexpected match result")
+	}
+	s = &subscription{all: true}
+	if !s.match(EventUserSignIn) {
+		t.Error("expect all events matched")
+	}
+	if !ValidEvent(EventAll) || !ValidEvent(EventGroupMemberAdded) || ValidEvent("unknown") {
+		t.Error("unexpected valid event result")
+	}
+}
+
+func TestPost(t *testing.T) {
+	d := &webhookdao.WebhookDelivery{
+		ID:      1,
+		EventID: "e1",
+		Event:   EventChatMessage,
+		Payload: `{"Event":"message.chat"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if r.Header.Get(HeaderSignature) != Sign("secret", ts, []byte(d.Payload)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != EventChatMessage || r.Header.Get(HeaderDelivery) != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	code, err := post(httpClient, srv.URL, "secret", d)
+	if err != nil || code != http.StatusNoContent {
+		t.Errorf("expect delivered, got %d %v", code, err)
+	}
+	code, err = post(httpClient, srv.URL, "wrong", d)
+	if err == nil || code != http.StatusUnauthorized {
+		t.Errorf("expect rejected, got %d %v", code, err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package ahocorasick 多模式串匹配, 一次扫描文本找出所有模式串的出现位置, 匹配时忽略大小写
+package ahocorasick
+
+import "unicode"
+
+type node struct {
+	next map[rune]int32
+	fail int32
+	// out 以该节点结尾的模式串, 包括通过失败指针可达的后缀模式串
+	out []int
+}
+
+// Match 一次匹配, Start 和 End 为原文中的字符 (rune) 下标, 不包括 End
+type Match struct {
+	// Pattern 匹配到的模式串下标
+	Pattern int
+	Start   int
+	End     int
+}
+
+// Automaton 构建完成后只读, 可以并发匹配
+type Automaton struct {
+	nodes    []*node
+	patterns []string
+	// lengths 模式串的字符数
+	lengths []int
+}
+
+func New(patterns []string) *Automaton {
+	a := &Automaton{
+		nodes:    []*node{{next: map[rune]int32{}}},
+		patterns: patterns,
+		lengths:  make([]int, len(patterns)),
+	}
+	for i, p := range patterns {
+		a.insert(i, p)
+	}
+	a.build()
+	return a
+}
+
+func (a *Automaton) insert(index int, pattern string) {
+	if len(pattern) == 0 {
+		return
+	}
+	a.lengths[index] = len([]rune(pattern))
+	cur := int32(0)
+	for _, r := range pattern {
+		r = unicode.ToLower(r)
+		n, ok := a.nodes[cur].next[r]
+		if !ok {
+			n = int32(len(a.nodes))
+			a.nodes = append(a.nodes, &node{next: map[rune]int32{}})
+			a.nodes[cur].next[r] = n
+		}
+		cur = n
+	}
+	a.nodes[cur].out = append(a.nodes[cur].out, index)
+}
+
+// build 按层次遍历设置失败指针, 并合并后缀节点的输出
+func (a *Automaton) build() {
+	var queue []int32
+	for _, n := range a.nodes[0].next {
+		queue = append(queue, n)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range a.nodes[cur].next {
+			f := a.nodes[cur].fail
+			for {
+				if n, ok := a.nodes[f].next[r]; ok {
+					a.nodes[child].fail = n
+					break
+				}
+				if f == 0 {
+					a.nodes[child].fail = 0
+					break
+				}
+				f = a.nodes[f].fail
+			}
+			a.nodes[child].out = append(a.nodes[child].out, a.nodes[a.nodes[child].fail].out...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 返回文本中所有模式串的出现位置, 按结束位置排序, 相互重叠的匹配都会返回
+func (a *Automaton) FindAll(text string) []Match {
+	var ms []Match
+	cur := int32(0)
+	i := 0
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		for {
+			if n, ok := a.nodes[cur].next[r]; ok {
+				cur = n
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = a.nodes[cur].fail
+		}
+		i++
+		for _, p := range a.nodes[cur].out {
+			ms = append(ms, Match{Pattern: p, Start: i - a.lengths[p], End: i})
+		}
+	}
+	return ms
+}
+
+// Pattern 返回下标对应的模式串
+func (a *Automaton) Pattern(index int) string {
+	return a.patterns[index]
+}
+
+// Len 模式串数量
+func (a *Automaton) Len() int {
+	return len(a.patterns)
+}
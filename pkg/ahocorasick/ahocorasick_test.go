@@ -0,0 +1,42 @@
+package ahocorasick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutomaton_FindAll(t *testing.T) {
+	a := New([]string{"he", "she", "his", "hers"})
+	ms := a.FindAll("ushers")
+	expect := []Match{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}
+	if !reflect.DeepEqual(ms, expect) {
+		t.Errorf("expect %v, got %v", expect, ms)
+	}
+}
+
+func TestAutomaton_FindAllUnicode(t *testing.T) {
+	a := New([]string{"敏感词", "感", "BAD"})
+	ms := a.FindAll("这是敏感词, bad word")
+	expect := []Match{
+		{Pattern: 1, Start: 3, End: 4},
+		{Pattern: 0, Start: 2, End: 5},
+		{Pattern: 2, Start: 7, End: 10},
+	}
+	if !reflect.DeepEqual(ms, expect) {
+		t.Errorf("expect %v, got %v", expect, ms)
+	}
+}
+
+func TestAutomaton_NoMatch(t *testing.T) {
+	a := New([]string{"abc", ""})
+	if ms := a.FindAll("ababab"); len(ms) != 0 {
+		t.Errorf("expect no match, got %v", ms)
+	}
+	if ms := New(nil).FindAll("abc"); len(ms) != 0 {
+		t.Errorf("expect no match, got %v", ms)
+	}
+}
@@ -0,0 +1,122 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidUrl    = errors.New("invalid url")
+	ErrForbiddenAddr = errors.New("forbidden address, private, loopback and link-local addresses are not allowed")
+)
+
+// forbiddenNets 不允许服务端主动访问的地址段, 包括内网, 本机, 链路本地 (云服务元数据地址 169.254.169.254 在其中) 等
+var forbiddenNets = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseCIDRs(cidr ...string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, c := range cidr {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// IsPublicIP 地址是否为公网地址
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, n := range forbiddenNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckUrl 检查 http(s) 地址, 解析域名后任一地址不是公网地址时返回 ErrForbiddenAddr.
+// 解析结果在请求时可能变化, 发送请求时还需要使用 NewClient 创建的客户端在连接时检查
+func CheckUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidUrl
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrForbiddenAddr
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ErrInvalidUrl
+	}
+	for _, a := range addrs {
+		if !IsPublicIP(a.IP) {
+			return ErrForbiddenAddr
+		}
+	}
+	return nil
+}
+
+// control 在建立连接前检查实际连接的地址, 防止域名解析到内网地址
+func control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicIP(net.ParseIP(host)) {
+		return ErrForbiddenAddr
+	}
+	return nil
+}
+
+// NewClient 创建只能访问公网地址的 http 客户端, 不使用代理
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: time.Second * 10,
+		Control: control,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     time.Second * 90,
+			TLSHandshakeTimeout: time.Second * 10,
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package netguard
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.20.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.100.100.200": false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00:ec2::254":   false,
+		"::ffff:10.0.0.1": false,
+	}
+	for ip, public := range cases {
+		if IsPublicIP(net.ParseIP(ip)) != public {
+			t.Errorf("IsPublicIP(%s) expect %v", ip, public)
+		}
+	}
+}
+
+func TestCheckUrl(t *testing.T) {
+	cases := map[string]error{
+		"https://8.8.8.8/hook":            nil,
+		"ftp://8.8.8.8/hook":              ErrInvalidUrl,
+		"http:///hook":                    ErrInvalidUrl,
+		"http://127.0.0.1:8080/hook":      ErrForbiddenAddr,
+		"http://[::1]/hook":               ErrForbiddenAddr,
+		"http://169.254.169.254/latest/":  ErrForbiddenAddr,
+		"http://localhost/hook":           ErrForbiddenAddr,
+		"https://192.168.0.10:8443/a?b=1": ErrForbiddenAddr,
+	}
+	for u, expect := range cases {
+		if err := CheckUrl(u); err != expect {
+			t.Errorf("CheckUrl(%s) expect %v, got %v", u, expect, err)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second).Get(srv.URL)
+	if err == nil {
+		t.Error("expect loopback request rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxTokenLen 单个词的最大长度, 超出部分截断
+	maxTokenLen = 32
+	// maxTokens 单个文本最多索引的词数
+	maxTokens = 512
+)
+
+// Tokenize 索引分词, 拉丁字母和数字按单词切分并转为小写, 中日韩文字按单字和相邻两字切分, 结果去重
+func Tokenize(text string) []string {
+	return tokenize(text, true)
+}
+
+// QueryTokens 查询分词, 与 Tokenize 规则一致, 但连续两个以上的中日韩文字只使用相邻两字, 减少需要匹配的词
+func QueryTokens(text string) []string {
+	return tokenize(text, false)
+}
+
+func tokenize(text string, unigram bool) []string {
+	var tokens []string
+	seen := map[string]bool{}
+	add := func(t string) {
+		if len(tokens) >= maxTokens || seen[t] {
+			return
+		}
+		seen[t] = true
+		tokens = append(tokens, t)
+	}
+
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			if len(word) > maxTokenLen {
+				word = word[:maxTokenLen]
+			}
+			add(strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCjk := func() {
+		if len(cjk) == 1 {
+			add(string(cjk))
+		} else if len(cjk) > 1 {
+			for i := range cjk {
+				if unigram {
+					add(string(cjk[i]))
+				}
+				if i+1 < len(cjk) {
+					add(string(cjk[i : i+2]))
+				}
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCjk(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCjk()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCjk()
+		}
+	}
+	flushWord()
+	flushCjk()
+	return tokens
+}
+
+func isCjk(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		text   string
+		tokens []string
+	}{
+		{"Hello, World hello", []string{"hello", "world"}},
+		{"今天天气", []string{"今", "今天", "天", "天天", "天气", "气"}},
+		{"go语言2022", []string{"go", "语", "语言", "言", "2022"}},
+		{"", nil},
+	}
+	for _, c := range cases {
+		tokens := Tokenize(c.text)
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("Tokenize(%q) = %v, expect %v", c.text, tokens, c.tokens)
+		}
+	}
+}
+
+func TestQueryTokens(t *testing.T) {
+	cases := []struct {
+		text   string
+		tokens []string
+	}{
+		{"天气", []string{"天气"}},
+		{"今天天气", []string{"今天", "天天", "天气"}},
+		{"天", []string{"天"}},
+		{"Go 语言", []string{"go", "语言"}},
+	}
+	for _, c := range cases {
+		tokens := QueryTokens(c.text)
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("QueryTokens(%q) = %v, expect %v", c.text, tokens, c.tokens)
+		}
+	}
+}
+
+func TestTokenize_Limit(t *testing.T) {
+	long := ""
+	for i := 0; i < 40; i++ {
+		long += "a"
+	}
+	tokens := Tokenize(long)
+	if len(tokens) != 1 || len(tokens[0]) != maxTokenLen {
+		t.Errorf("long word not truncated, %v", tokens)
+	}
+}
@@ -286,7 +286,8 @@ func (w *TimingWheel) After(timeout time.Duration) *Task {
 	//offset := int(float64(TTL) / float64(w.interval))
 	offset := int(math.Floor(float64(timeout.Milliseconds())/float64(w.interval.Milliseconds()) + 1.0/2.0))
 
-	ch := make(chan struct{})
+	// 只会发送一次, 带缓冲避免只设置回调不读取 C 的任务阻塞执行协程
+	ch := make(chan struct{}, 1)
 
 	t := &Task{
 		offset: offset,
@@ -102,10 +102,12 @@ func (c *Client) ClientLogout(uid int64, device int64) error {
 	return c.DispatchGateway(uid, nsqMsg)
 }
 
-func (c *Client) EnqueueMessage(uid int64, device int64, msg *message.Message) error {
+func (c *Client) EnqueueMessage(uid int64, device int64, exclude bool, msg *message.Message) error {
 	data, _ := anypb.New(&pb_rpc.EnqueueMessageRequest{
 		Uid:     uid,
 		Message: msg.GetProtobuf(),
+		Device:  device,
+		Exclude: exclude,
 	})
 	nsqMsg := &pb_rpc.NSQGatewayMessage{
 		Operate: pb_rpc.NSQGatewayMessage_PUSH_MSG,
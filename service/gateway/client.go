@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/glide-im/glideim/im/client"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/pkg/logger"
 	rpc2 "github.com/glide-im/glideim/pkg/rpc"
 	"github.com/glide-im/glideim/protobuf/gen/pb_rpc"
 )
@@ -13,13 +14,21 @@ type Client struct {
 }
 
 func NewClient(options *rpc2.ClientOptions) (*Client, error) {
+	ret, err := newClient(options)
+	if err != nil {
+		return nil, err
+	}
+	client.SetInterfaceImpl(ret)
+	return ret, nil
+}
+
+func newClient(options *rpc2.ClientOptions) (*Client, error) {
 	ret := &Client{}
 	var err error
 	ret.Cli, err = rpc2.NewBaseClient(options)
 	if err != nil {
 		return nil, err
 	}
-	client.SetInterfaceImpl(ret)
 	return ret, nil
 }
 
@@ -46,11 +55,13 @@ func (c *Client) ClientLogout(uid int64, device int64) error {
 	return nil
 }
 
-func (c *Client) EnqueueMessage(uid int64, device int64, msg *message.Message) error {
+func (c *Client) EnqueueMessage(uid int64, device int64, exclude bool, msg *message.Message) error {
 
 	req := &pb_rpc.EnqueueMessageRequest{
 		Uid:     uid,
 		Message: msg.GetProtobuf(),
+		Device:  device,
+		Exclude: exclude,
 	}
 	resp := &pb_rpc.Response{}
 	err := c.Call(getTagContext(uid, -1), "EnqueueMessage", req, resp)
@@ -60,6 +71,32 @@ func (c *Client) EnqueueMessage(uid int64, device int64, msg *message.Message) e
 	return nil
 }
 
+// IsOnline 通过 rpc 查询用户是否有在线的设备, 查询失败时视为不在线
+func (c *Client) IsOnline(uid int64) bool {
+	resp := &pb_rpc.Response{}
+	err := c.Call(getTagContext(uid, -1), "IsOnline", &pb_rpc.UidRequest{Uid: uid}, resp)
+	if err != nil {
+		logger.E("query user online error %v", err)
+		return false
+	}
+	return resp.Ok
+}
+
+// OnlineUsers 通过一次 rpc 批量查询有在线设备的用户, 查询失败时视为都不在线
+func (c *Client) OnlineUsers(uid ...int64) map[int64]bool {
+	online := map[int64]bool{}
+	resp := &pb_rpc.UidsResponse{}
+	err := c.Call(getTagContext(0, -1), "OnlineUsers", &pb_rpc.UidsRequest{Uid: uid}, resp)
+	if err != nil {
+		logger.E("query users online error %v", err)
+		return online
+	}
+	for _, u := range resp.GetUid() {
+		online[u] = true
+	}
+	return online
+}
+
 func (c *Client) isDeviceOnline(uid, device int64) bool {
 	return true
 }
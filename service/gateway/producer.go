@@ -39,7 +39,7 @@ func (g gateway) ClientLogout(uid int64, device int64) error {
 	return producer.Publish("im_logout_"+topic, nil)
 }
 
-func (g gateway) EnqueueMessage(uid int64, device int64, message *message.Message) error {
+func (g gateway) EnqueueMessage(uid int64, device int64, exclude bool, message *message.Message) error {
 	topic, err := route.GetGateway(uid, device)
 	if err != nil {
 		return err
@@ -25,6 +25,20 @@ func SetupClient(configs *service.ClientConfig) error {
 	return nil
 }
 
+// SetupPresence 通过 rpc 查询网关中用户的在线状态, 用于通过 dispatch 投递消息的节点判断是否需要离线推送
+func SetupPresence(configs *service.Configs) error {
+
+	options := configs.Gateway.Client.ToClientOptions()
+	options.EtcdServers = configs.Etcd.Servers
+
+	cli, err := newClient(options)
+	if err != nil {
+		return err
+	}
+	client.SetPresenceImpl(cli)
+	return nil
+}
+
 // RunServer TODO 2022-3-24 run nsq
 func RunServer(configs *service.Configs) error {
 
@@ -30,6 +30,25 @@ func (s *Server) ClientLogout(ctx context.Context, request *pb_rpc.GatewayLogout
 
 func (s *Server) EnqueueMessage(ctx context.Context, request *pb_rpc.EnqueueMessageRequest, reply *pb_rpc.Response) error {
 	m := message.FromProtobuf(request.GetMessage())
-	err := client.EnqueueMessageToDevice(request.GetUid(), 0, m)
+	var err error
+	if request.GetExclude() {
+		err = client.EnqueueMessageToOtherDevices(request.GetUid(), request.GetDevice(), m)
+	} else {
+		err = client.EnqueueMessageToDevice(request.GetUid(), request.GetDevice(), m)
+	}
 	return err
 }
+
+// IsOnline 查询用户在本网关是否有在线的设备
+func (s *Server) IsOnline(ctx context.Context, request *pb_rpc.UidRequest, reply *pb_rpc.Response) error {
+	reply.Ok = client.IsOnline(request.GetUid())
+	return nil
+}
+
+// OnlineUsers 批量查询在本网关有在线设备的用户
+func (s *Server) OnlineUsers(ctx context.Context, request *pb_rpc.UidsRequest, reply *pb_rpc.UidsResponse) error {
+	for u := range client.OnlineUsers(request.GetUid()...) {
+		reply.Uid = append(reply.Uid, u)
+	}
+	return nil
+}
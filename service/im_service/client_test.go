@@ -19,7 +19,7 @@ func TestNewClient(t *testing.T) {
 		t.Error(err)
 	}
 
-	err = cli.EnqueueMessage(1, 1, message.NewEmptyMessage())
+	err = cli.EnqueueMessage(1, 1, false, message.NewEmptyMessage())
 
 	if err != nil {
 		t.Error(err)
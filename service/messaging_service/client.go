@@ -2,11 +2,17 @@ package messaging_service
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/glide-im/glideim/config"
 	"github.com/glide-im/glideim/im/message"
+	"github.com/glide-im/glideim/im/messaging"
 	"github.com/glide-im/glideim/pkg/rpc"
 	"github.com/glide-im/glideim/protobuf/gen/pb_rpc"
 )
 
+// MetaApiKey 服务端发送消息时在请求元数据中携带的密钥
+const MetaApiKey = "api_key"
+
 type Client struct {
 	rpc.Cli
 }
@@ -30,3 +36,45 @@ func (c *Client) HandleMessage(from int64, device int64, message *message.Messag
 
 	return c.Call(context.TODO(), "HandleMessage", &request, &pb_rpc.Response{})
 }
+
+// SendMessages 以系统账号或机器人批量发送消息, apiKey 为配置 ServerApi.Keys 中的密钥
+func (c *Client) SendMessages(apiKey string, req *messaging.SendRequest) ([]*messaging.SendResult, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	reply := &pb_rpc.JsonString{}
+	ctx := rpc.NewCtx().PutReqExtra(MetaApiKey, apiKey)
+	err = c.Call(ctx, "SendMessages", &pb_rpc.JsonString{Json: string(b)}, reply)
+	if err != nil {
+		return nil, sendMessagesError(err)
+	}
+	var results []*messaging.SendResult
+	err = json.Unmarshal([]byte(reply.GetJson()), &results)
+	return results, err
+}
+
+// sendMessages 以配置 ServerApi.Keys 中的第一个密钥发送, 用于 api 服务将服务端发送消息请求转发给消息服务
+func (c *Client) sendMessages(req *messaging.SendRequest) ([]*messaging.SendResult, error) {
+	key := ""
+	if len(config.ServerApi.Keys) > 0 {
+		key = config.ServerApi.Keys[0]
+	}
+	return c.SendMessages(key, req)
+}
+
+// sendMessagesError 将 rpc 返回的错误还原为 messaging 中定义的请求参数错误
+func sendMessagesError(err error) error {
+	for _, e := range []error{
+		messaging.ErrServerApiDisabled,
+		messaging.ErrInvalidSender,
+		messaging.ErrInvalidKind,
+		messaging.ErrInvalidTargets,
+		messaging.ErrEmptyContent,
+	} {
+		if err.Error() == e.Error() {
+			return e
+		}
+	}
+	return err
+}
@@ -17,6 +17,7 @@ func SetupClient(configs *service.Configs) error {
 	}
 	messaging.SetInterfaceImpl(cli.HandleMessage)
 	client.SetMessageHandler(cli.HandleMessage)
+	messaging.SetSendInterfaceImpl(cli.sendMessages)
 	return nil
 }
 
@@ -2,6 +2,8 @@ package messaging_service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"github.com/glide-im/glideim/im/message"
 	"github.com/glide-im/glideim/im/messaging"
 	"github.com/glide-im/glideim/pkg/rpc"
@@ -25,3 +27,26 @@ func (s *Server) HandleMessage(ctx context.Context, request *pb_rpc.MessagingHan
 
 	return messaging.HandleMessage(request.GetId(), request.GetDevice(), m)
 }
+
+// SendMessages 服务端批量发送消息, 请求和响应分别为 json 编码的 messaging.SendRequest 和 []*messaging.SendResult,
+// 请求元数据 MetaApiKey 需要是配置中的密钥
+func (s *Server) SendMessages(ctx context.Context, request *pb_rpc.JsonString, reply *pb_rpc.JsonString) error {
+	key, _ := rpc.NewCtxFrom(ctx).GetReqExtra(MetaApiKey)
+	if !messaging.CheckApiKey(key) {
+		return errors.New("invalid api key")
+	}
+	req := &messaging.SendRequest{}
+	if err := json.Unmarshal([]byte(request.GetJson()), req); err != nil {
+		return err
+	}
+	results, err := messaging.SendMessages(req)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	reply.Json = string(b)
+	return nil
+}
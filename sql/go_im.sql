@@ -17,6 +17,38 @@
 SET NAMES utf8mb4;
 SET FOREIGN_KEY_CHECKS = 0;
 
+-- ----------------------------
+-- Table structure for im_bot
+-- ----------------------------
+DROP TABLE IF EXISTS `im_bot`;
+CREATE TABLE `im_bot`  (
+  `uid` bigint NOT NULL,
+  `owner` bigint NOT NULL,
+  `name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `avatar` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `token_hash` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `webhook_id` bigint NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`uid`) USING BTREE,
+  UNIQUE INDEX `token_hash`(`token_hash`) USING BTREE,
+  INDEX `owner`(`owner`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_bot_update
+-- ----------------------------
+DROP TABLE IF EXISTS `im_bot_update`;
+CREATE TABLE `im_bot_update`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `bot_uid` bigint NOT NULL,
+  `action` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `payload` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `bot_uid_id`(`bot_uid`, `id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
 -- ----------------------------
 -- Table structure for im_chat_message
 -- ----------------------------
@@ -32,7 +64,10 @@ CREATE TABLE `im_chat_message`  (
   `create_at` bigint NOT NULL,
   `content` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
   `status` int NOT NULL,
-  PRIMARY KEY (`m_id`) USING BTREE
+  `edit_at` bigint NOT NULL DEFAULT 0,
+  `expire_at` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`m_id`) USING BTREE,
+  INDEX `expire_at`(`expire_at`) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 123432 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
@@ -67,12 +102,12 @@ CREATE TABLE `im_group_member_model`  (
 -- ----------------------------
 DROP TABLE IF EXISTS `im_group_member_msg_state`;
 CREATE TABLE `im_group_member_msg_state`  (
-  `mb_id` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
-  `g_id` bigint NULL DEFAULT NULL,
-  `uid` bigint NULL DEFAULT NULL,
+  `gid` bigint NOT NULL,
+  `uid` bigint NOT NULL,
   `last_ack_m_id` bigint NULL DEFAULT NULL,
   `last_ack_seq` bigint NULL DEFAULT NULL,
-  PRIMARY KEY (`mb_id`) USING BTREE
+  PRIMARY KEY (`gid`, `uid`) USING BTREE,
+  INDEX `gid_last_ack_seq`(`gid`, `last_ack_seq`) USING BTREE
 ) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
@@ -89,7 +124,11 @@ CREATE TABLE `im_group_message`  (
   `content` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
   `status` int NOT NULL,
   `recall_by` int NOT NULL,
-  PRIMARY KEY (`m_id`) USING BTREE
+  `edit_at` bigint NOT NULL DEFAULT 0,
+  `expire_at` bigint NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`m_id`) USING BTREE,
+  INDEX `expire_at`(`expire_at`) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 1231241239 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
@@ -114,6 +153,7 @@ CREATE TABLE `im_group_model`  (
   `avatar` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL,
   `mute` tinyint(1) NULL DEFAULT NULL,
   `flag` int NULL DEFAULT NULL,
+  `msg_ttl` bigint NOT NULL DEFAULT 0,
   `create_at` bigint NULL DEFAULT NULL,
   PRIMARY KEY (`gid`) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 19 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
@@ -123,11 +163,137 @@ CREATE TABLE `im_group_model`  (
 -- ----------------------------
 DROP TABLE IF EXISTS `im_group_msg_seq`;
 CREATE TABLE `im_group_msg_seq`  (
-  `gid` bigint NOT NULL AUTO_INCREMENT,
-  `seq` bigint NULL DEFAULT NULL,
-  `step` bigint NULL DEFAULT NULL,
+  `gid` bigint NOT NULL,
+  `seq` bigint NOT NULL DEFAULT 0,
+  `step` bigint NOT NULL DEFAULT 200,
   PRIMARY KEY (`gid`) USING BTREE
-) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_id_segment
+-- ----------------------------
+DROP TABLE IF EXISTS `im_id_segment`;
+CREATE TABLE `im_id_segment`  (
+  `biz_tag` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `max_id` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`biz_tag`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_inbox_event
+-- ----------------------------
+DROP TABLE IF EXISTS `im_inbox_event`;
+CREATE TABLE `im_inbox_event`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `uid` bigint NOT NULL,
+  `seq` bigint NOT NULL,
+  `action` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `m_id` bigint NOT NULL DEFAULT 0,
+  `gid` bigint NOT NULL DEFAULT 0,
+  `data` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uid_seq`(`uid`, `seq`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_inbox_seq
+-- ----------------------------
+DROP TABLE IF EXISTS `im_inbox_seq`;
+CREATE TABLE `im_inbox_seq`  (
+  `uid` bigint NOT NULL,
+  `seq` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`uid`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_message_clear
+-- ----------------------------
+DROP TABLE IF EXISTS `im_message_clear`;
+CREATE TABLE `im_message_clear`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `uid` bigint NOT NULL,
+  `target` bigint NOT NULL,
+  `group` tinyint(1) NOT NULL DEFAULT 0,
+  `position` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uid_target_group`(`uid`, `target`, `group`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_message_hidden
+-- ----------------------------
+DROP TABLE IF EXISTS `im_message_hidden`;
+CREATE TABLE `im_message_hidden`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `uid` bigint NOT NULL,
+  `m_id` bigint NOT NULL,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uid_m_id`(`uid`, `m_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_message_reaction
+-- ----------------------------
+DROP TABLE IF EXISTS `im_message_reaction`;
+CREATE TABLE `im_message_reaction`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `m_id` bigint NOT NULL,
+  `uid` bigint NOT NULL,
+  `emoji` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `m_id_uid_emoji`(`m_id`, `uid`, `emoji`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_message_revision
+-- ----------------------------
+DROP TABLE IF EXISTS `im_message_revision`;
+CREATE TABLE `im_message_revision`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `m_id` bigint NOT NULL,
+  `content` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `edit_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `m_id`(`m_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_message_token
+-- ----------------------------
+DROP TABLE IF EXISTS `im_message_token`;
+CREATE TABLE `im_message_token`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `token` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `m_id` bigint NOT NULL,
+  `group` tinyint(1) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `token_group_m_id`(`token`, `group`, `m_id`) USING BTREE,
+  INDEX `m_id`(`m_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_moderation_review
+-- ----------------------------
+DROP TABLE IF EXISTS `im_moderation_review`;
+CREATE TABLE `im_moderation_review`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `m_id` bigint NOT NULL,
+  `group` tinyint(1) NOT NULL,
+  `from` bigint NOT NULL,
+  `to` bigint NOT NULL,
+  `content` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `hits` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `reason` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` tinyint NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `status_id`(`status`, `id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Table structure for im_offline_message
@@ -137,9 +303,88 @@ CREATE TABLE `im_offline_message`  (
   `id` bigint NOT NULL AUTO_INCREMENT,
   `m_id` bigint NULL DEFAULT NULL,
   `uid` bigint NULL DEFAULT NULL,
-  PRIMARY KEY (`id`) USING BTREE
+  `create_at` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `m_id`(`m_id`) USING BTREE,
+  INDEX `uid_id`(`uid`, `id`) USING BTREE,
+  INDEX `create_at`(`create_at`) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 136 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
+-- ----------------------------
+-- Table structure for im_offline_missed
+-- ----------------------------
+DROP TABLE IF EXISTS `im_offline_missed`;
+CREATE TABLE `im_offline_missed`  (
+  `uid` bigint NOT NULL,
+  `count` bigint NOT NULL DEFAULT 0,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`uid`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_push_mute
+-- ----------------------------
+DROP TABLE IF EXISTS `im_push_mute`;
+CREATE TABLE `im_push_mute`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `uid` bigint NOT NULL,
+  `target` bigint NOT NULL,
+  `group` tinyint(1) NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uid_target_group`(`uid`, `target`, `group`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_push_setting
+-- ----------------------------
+DROP TABLE IF EXISTS `im_push_setting`;
+CREATE TABLE `im_push_setting`  (
+  `uid` bigint NOT NULL,
+  `dnd_start` int NOT NULL DEFAULT 0,
+  `dnd_end` int NOT NULL DEFAULT 0,
+  `tz_offset` int NOT NULL DEFAULT 0,
+  `hide_preview` tinyint(1) NOT NULL DEFAULT 0,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`uid`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_push_token
+-- ----------------------------
+DROP TABLE IF EXISTS `im_push_token`;
+CREATE TABLE `im_push_token`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `uid` bigint NOT NULL,
+  `device` bigint NOT NULL,
+  `platform` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `token` varchar(4096) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `create_at` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uid_device`(`uid`, `device`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_scheduled_message
+-- ----------------------------
+DROP TABLE IF EXISTS `im_scheduled_message`;
+CREATE TABLE `im_scheduled_message`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `from` bigint NOT NULL,
+  `to` bigint NOT NULL,
+  `group` tinyint(1) NOT NULL DEFAULT 0,
+  `type` int NOT NULL,
+  `content` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `send_at` bigint NOT NULL,
+  `status` int NOT NULL DEFAULT 0,
+  `m_id` bigint NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `status_send_at`(`status`, `send_at`) USING BTREE,
+  INDEX `from`(`from`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
 -- ----------------------------
 -- Table structure for im_user
 -- ----------------------------
@@ -156,4 +401,58 @@ CREATE TABLE `im_user`  (
   UNIQUE INDEX `account`(`account`) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 543629 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
+-- ----------------------------
+-- Table structure for im_webhook
+-- ----------------------------
+DROP TABLE IF EXISTS `im_webhook`;
+CREATE TABLE `im_webhook`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `url` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `secret` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `events` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `description` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  `bot` tinyint(1) NOT NULL DEFAULT 0,
+  `create_at` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_webhook_attempt
+-- ----------------------------
+DROP TABLE IF EXISTS `im_webhook_attempt`;
+CREATE TABLE `im_webhook_attempt`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `delivery_id` bigint NOT NULL,
+  `attempt` int NOT NULL,
+  `status_code` int NOT NULL,
+  `error` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `duration` bigint NOT NULL,
+  `create_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `delivery_id`(`delivery_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for im_webhook_delivery
+-- ----------------------------
+DROP TABLE IF EXISTS `im_webhook_delivery`;
+CREATE TABLE `im_webhook_delivery`  (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `webhook_id` bigint NOT NULL,
+  `event_id` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  `event` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `payload` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` tinyint NOT NULL DEFAULT 0,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` bigint NOT NULL,
+  `last_error` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `create_at` bigint NOT NULL,
+  `update_at` bigint NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `status_next_attempt_at`(`status`, `next_attempt_at`) USING BTREE,
+  INDEX `webhook_id_status`(`webhook_id`, `status`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
 SET FOREIGN_KEY_CHECKS = 1;
@@ -38,12 +38,11 @@ create TABLE `im_chat_message` (
 -- ----------------------------
 drop table IF EXISTS `im_group_member_msg_state`;
 create TABLE `im_group_member_msg_state`  (
-  `mb_id` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '群成员 ID, gid+uid 拼接',
-  `g_id` bigint NOT NULL COMMENT '群 id',
+  `gid` bigint NOT NULL COMMENT '群 id',
   `uid` bigint NOT NULL COMMENT '成员id',
   `last_ack_m_id` bigint NOT NULL COMMENT '最后一次确认收到群消息id',
   `last_ack_seq` bigint NOT NULL COMMENT '最后一次确认收到消息的seq',
-  PRIMARY KEY (`mb_id`) USING BTREE
+  PRIMARY KEY (`gid`, `uid`) USING BTREE
 ) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------